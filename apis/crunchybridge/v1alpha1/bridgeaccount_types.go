@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defines the Crunchy Bridge account credentials used by objects referencing
+// this BridgeAccount
+type BridgeAccountSpec struct {
+	// identifies the secret holding the API key and secret for the account
+	CredentialsRef NamespacedName `json:"credentials_ref"`
+	// names the secret field holding the API key
+	// +kubebuilder:default=api_key
+	// +optional
+	KeyField string `json:"key_field,omitempty"`
+	// names the secret field holding the API secret
+	// +kubebuilder:default=api_secret
+	// +optional
+	SecretField string `json:"secret_field,omitempty"`
+	// overrides the Crunchy Bridge API URL for the account.
+	// Defaults to the URL the operator was started with
+	// +optional
+	APIURL string `json:"api_url,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// BridgeAccount is the Schema for the bridgeaccounts API
+type BridgeAccount struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BridgeAccountSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BridgeAccountList contains a list of BridgeAccount
+type BridgeAccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BridgeAccount `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BridgeAccount{}, &BridgeAccountList{})
+}
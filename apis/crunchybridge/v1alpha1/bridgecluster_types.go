@@ -17,15 +17,73 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	PhaseUnknown  = ""
-	PhasePending  = "Pending"
-	PhaseCreating = "Creating"
-	PhaseReady    = "Ready"
-	PhaseDeleting = "Deleting"
+	PhaseUnknown   = ""
+	PhasePending   = "Pending"
+	PhaseCreating  = "Creating"
+	PhaseReady     = "Ready"
+	PhaseUpdating  = "Updating"
+	PhaseUpgrading = "Upgrading"
+	PhaseDeleting  = "Deleting"
+	PhaseFailed    = "Failed"
+	PhaseStalled   = "Stalled"
+	PhaseLost      = "Lost"
+)
+
+const (
+	// DeletionPolicyDelete removes the Crunchy Bridge cluster along with
+	// the BridgeCluster object
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyRetain leaves the Crunchy Bridge cluster running when
+	// the BridgeCluster object is deleted
+	DeletionPolicyRetain = "Retain"
+)
+
+const (
+	// ProvisioningFailurePolicyFail leaves a cluster which failed or stalled
+	// during provisioning in place for inspection until the spec changes
+	ProvisioningFailurePolicyFail = "Fail"
+	// ProvisioningFailurePolicyRetry deletes a cluster which failed or
+	// stalled during provisioning and requests a new one once it is gone.
+	// Create requests Bridge rejected are only retried after a spec change.
+	ProvisioningFailurePolicyRetry = "Retry"
+)
+
+const (
+	// RecoveryPolicyIgnore leaves a BridgeCluster whose cluster was deleted
+	// outside the operator in the Lost phase
+	RecoveryPolicyIgnore = "Ignore"
+	// RecoveryPolicyRecreate provisions a replacement for a cluster deleted
+	// outside the operator
+	RecoveryPolicyRecreate = "Recreate"
+)
+
+const (
+	// UpgradePolicyAutomatic starts a major version upgrade as soon as the
+	// spec asks for a newer version
+	UpgradePolicyAutomatic = "Automatic"
+	// UpgradePolicyManual waits for the AnnotationApproveUpgrade annotation
+	// before starting a major version upgrade
+	UpgradePolicyManual = "Manual"
+)
+
+const (
+	// AnnotationDeletionProtection, when set to "true", prevents the
+	// finalizer from completing until the annotation is removed
+	AnnotationDeletionProtection = "crunchybridge.crunchydata.com/deletion-protection"
+
+	// AnnotationForceRemove, when set to "true" on an object being deleted,
+	// releases the finalizer without waiting for the Crunchy Bridge cluster
+	// to be removed, for when the API can no longer be reached
+	AnnotationForceRemove = "crunchybridge.crunchydata.com/force-remove"
+
+	// AnnotationApproveUpgrade approves the major version upgrade to the
+	// version it is set to (e.g. "15") under the Manual upgrade policy
+	AnnotationApproveUpgrade = "crunchybridge.crunchydata.com/approve-upgrade"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -53,12 +111,96 @@ type BridgeClusterSpec struct {
 	Provider string `json:"provider"`
 	// identifies the requested deployment region within the provider (e.g. us-east-1)
 	Region string `json:"region"`
-	// selects the major version of PostgreSQL to deploy (e.g. 12, 13)
+	// selects the major version of PostgreSQL to deploy (e.g. 13, 14).
+	// Raising it upgrades the cluster in place, downgrades are refused.
+	// Defaults to 14 unless adopting a cluster
 	// +kubebuilder:validation:Minimum=12
-	PGMajorVer int `json:"pg_major_version"`
+	// +optional
+	PGMajorVer int `json:"pg_major_version,omitempty"`
 	// flags whether to deploy the additional nodes to enable high availability
 	// +optional
 	HighAvail bool `json:"enable_ha"`
+	// identifies an existing Crunchy Bridge cluster to adopt instead of
+	// creating a new one. The cluster's name, provider, region, team and
+	// major version must match the spec
+	// +optional
+	ClusterID string `json:"cluster_id,omitempty"`
+	// determines what happens to the Crunchy Bridge cluster when this object
+	// is deleted: Delete removes the cluster, Retain leaves it running
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy string `json:"deletion_policy,omitempty"`
+	// bounds how long the cluster may take to be removed once deletion is
+	// requested before the object reports deletion as stalled (e.g. 30m).
+	// Defaults to 30 minutes
+	// +optional
+	DeletionTimeout *metav1.Duration `json:"deletion_timeout,omitempty"`
+	// names a secret in the same namespace to be written with the host,
+	// port, database, user, password and full URI for the cluster's default
+	// connection role. The secret is kept in sync while the cluster exists
+	// +optional
+	ConnectionSecretRef *corev1.LocalObjectReference `json:"write_connection_secret_to_ref,omitempty"`
+	// names the BridgeAccount whose credentials manage this cluster.
+	// Defaults to the operator-wide credentials
+	// +optional
+	AccountRef string `json:"account_ref,omitempty"`
+	// bounds how long the cluster may take to become ready once creation
+	// is requested before it is marked Stalled (e.g. 30m, 2h).
+	// Defaults to one hour
+	// +optional
+	ProvisioningTimeout *metav1.Duration `json:"provisioning_timeout,omitempty"`
+	// determines what happens when provisioning fails or stalls: Fail leaves
+	// the cluster in place for inspection until the spec changes, Retry
+	// deletes it and requests a new one after a backoff once it is gone. A
+	// create request Bridge rejected is only retried after a spec change.
+	// +kubebuilder:validation:Enum=Fail;Retry
+	// +kubebuilder:default=Fail
+	// +optional
+	ProvisioningFailurePolicy string `json:"provisioning_failure_policy,omitempty"`
+	// determines what happens when the cluster is deleted outside the
+	// operator: Ignore leaves the object Lost, Recreate provisions a
+	// replacement with a new cluster ID. Adopted clusters are never recreated
+	// +kubebuilder:validation:Enum=Ignore;Recreate
+	// +kubebuilder:default=Ignore
+	// +optional
+	RecoveryPolicy string `json:"recovery_policy,omitempty"`
+	// lists the read replicas to maintain for the cluster. Replicas are
+	// created once the cluster is ready and removed when dropped from the list
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Replicas []ReplicaSpec `json:"replicas,omitempty"`
+	// determines when a raised pg_major_version is applied: Automatic starts
+	// the upgrade right away, Manual waits for the
+	// crunchybridge.crunchydata.com/approve-upgrade annotation to be set to
+	// the new version. A failed upgrade is not retried until the spec changes
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	// +kubebuilder:default=Automatic
+	// +optional
+	UpgradePolicy string `json:"upgrade_policy,omitempty"`
+}
+
+// defines a read replica of the BridgeCluster
+type ReplicaSpec struct {
+	// represents the replica name within Crunchy Bridge, must be unique per team.
+	// An existing replica of this name is not adopted
+	// +kubebuilder:validation:MinLength=5
+	Name string `json:"name"`
+	// identifies the Crunchy Bridge provisioning plan for the replica.
+	// Defaults to the plan of the cluster
+	// +optional
+	Plan string `json:"plan,omitempty"`
+	// identifies the cloud infrastructure provider for the replica.
+	// Defaults to the provider of the cluster
+	// +kubebuilder:validation:Enum=aws;gcp;azure
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// identifies the deployment region of the replica, which may differ from
+	// the cluster's for a cross-region replica. Defaults to the region of the
+	// cluster
+	// +optional
+	Region string `json:"region,omitempty"`
 }
 
 // defines the observed state of BridgeCluster
@@ -69,13 +211,62 @@ type BridgeClusterStatus struct {
 	//     pending - creation not yet started
 	//     creating - provisioning in progress
 	//     ready - cluster provisioning complete
+	//     updating - plan, storage or HA change in progress
+	//     upgrading - major version upgrade in progress
+	//     failed - provisioning failed, see message
+	//     stalled - provisioning exceeded its timeout
+	//     deleting - cluster removal requested, waiting for Bridge to finish
+	//     lost - cluster deleted outside the operator
 	Phase string `json:"phase"`
+	// provides detail on the current phase, such as why deletion is blocked
+	// +optional
+	Message string `json:"message,omitempty"`
 	// last status update from the controller, does not correlate to cluster.updated_at
 	Updated string `json:"last_update"`
 	// represents cluster detail from Crunchy Bridge
 	Cluster ClusterStatus `json:"cluster"`
 	// provides non-user specific connection information
 	Connect Connection `json:"connection"`
+	// represents the latest available observations of the cluster's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// represents the .metadata.generation last acted upon by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
+	// represents when creation of the cluster was last requested
+	// +optional
+	ProvisioningStarted string `json:"provisioning_started,omitempty"`
+	// counts the requests made to create the cluster
+	// +optional
+	ProvisioningAttempts int `json:"provisioning_attempts,omitempty"`
+	// represents when removal of the cluster was requested
+	// +optional
+	DeletionStarted string `json:"deletion_started,omitempty"`
+	// represents when the running major version upgrade was requested
+	// +optional
+	UpgradeStarted string `json:"upgrade_started,omitempty"`
+	// represents the read replicas of the cluster requested through the spec
+	// +optional
+	Replicas []ReplicaStatus `json:"replicas,omitempty"`
+}
+
+type ReplicaStatus struct {
+	// represents the replica name from the spec
+	Name string `json:"name"`
+	// represents the Crunchy Bridge cluster identifier of the replica
+	ID string `json:"id"`
+	// represents the replica state as reported by Crunchy Bridge
+	State string `json:"state"`
+	// represents the Crunchy Bridge provisioning plan for the replica
+	Plan string `json:"plan_id"`
+	// represents the infrastructure provider for the replica
+	ProviderID string `json:"provider_id"`
+	// represents the region location for the replica
+	RegionID string `json:"region_id"`
+	// represents the host to connect to for read-only queries
+	Host string `json:"host"`
 }
 
 type ClusterStatus struct {
@@ -86,6 +277,8 @@ type ClusterStatus struct {
 	// represents the ID of the team which owns the cluster
 	TeamID string `json:"team_id"`
 
+	// represents the Crunchy Bridge provisioning plan for the cluster
+	Plan string `json:"plan_id"`
 	// represents the plan-allocated CPUs for the cluster
 	CPU int `json:"cpu"`
 	// represents the plan-allocated memory in gigabytes
@@ -133,6 +326,10 @@ type BridgeCluster struct {
 	Status BridgeClusterStatus `json:"status,omitempty"`
 }
 
+func (in *BridgeCluster) GetStatusConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
 //+kubebuilder:object:root=true
 
 // BridgeClusterList contains a list of BridgeCluster
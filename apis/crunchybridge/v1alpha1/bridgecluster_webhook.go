@@ -0,0 +1,240 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultPGMajorVersion is deployed when the spec doesn't select a major
+// version
+const DefaultPGMajorVersion = 14
+
+// log is for logging in this package.
+var bridgeclusterlog = logf.Log.WithName("bridgecluster-resource")
+
+// BridgeCatalog gives the BridgeCluster webhooks read access to Crunchy
+// Bridge with the credentials of the BridgeAccount named by accountRef, the
+// operator-wide account if empty
+type BridgeCatalog interface {
+	// DefaultTeamID returns the team clusters are created in when the spec
+	// names none
+	DefaultTeamID(ctx context.Context, accountRef string) (string, error)
+	// Offerings returns the plans and regions available from provider,
+	// with ok false if the catalog doesn't list the provider
+	Offerings(ctx context.Context, accountRef, provider string) (plans, regions []string, ok bool, err error)
+}
+
+// bridgeClusterWebhook defaults and validates BridgeClusters. Checks which
+// need the catalog are skipped when it is unset or can't be reached, the
+// controller still reports requests Bridge rejects.
+type bridgeClusterWebhook struct {
+	catalog BridgeCatalog
+}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks
+// for BridgeCluster, consulting catalog for team and catalog checks
+func (r *BridgeCluster) SetupWebhookWithManager(mgr ctrl.Manager, catalog BridgeCatalog) error {
+	wh := &bridgeClusterWebhook{catalog: catalog}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(wh).
+		WithValidator(wh).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-crunchybridge-crunchydata-com-v1alpha1-bridgecluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=crunchybridge.crunchydata.com,resources=bridgeclusters,verbs=create,versions=v1alpha1,name=mbridgecluster.kb.io,admissionReviewVersions=v1
+
+// Default fills in team_id and pg_major_version when unset. The webhook is
+// only registered for creation, updates leaving them unset keep what Bridge
+// chose. Clusters being adopted through cluster_id are left alone, as the
+// defaults may not match the existing cluster.
+func (wh *bridgeClusterWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	r, ok := obj.(*BridgeCluster)
+	if !ok {
+		return fmt.Errorf("expected a BridgeCluster but got %T", obj)
+	}
+	bridgeclusterlog.Info("default", "name", r.Name)
+
+	if r.Spec.ClusterID != "" {
+		return nil
+	}
+	if r.Spec.PGMajorVer == 0 {
+		r.Spec.PGMajorVer = DefaultPGMajorVersion
+	}
+	if r.Spec.TeamID == "" && wh.catalog != nil {
+		teamID, err := wh.catalog.DefaultTeamID(ctx, r.Spec.AccountRef)
+		if err != nil {
+			// The controller looks the team up again at creation
+			bridgeclusterlog.Error(err, "unable to default team_id", "name", r.Name)
+			return nil
+		}
+		r.Spec.TeamID = teamID
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-crunchybridge-crunchydata-com-v1alpha1-bridgecluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=crunchybridge.crunchydata.com,resources=bridgeclusters,verbs=create;update,versions=v1alpha1,name=vbridgecluster.kb.io,admissionReviewVersions=v1
+
+// ValidateCreate checks plan, provider and region of the cluster and its
+// replicas against the catalog
+func (wh *bridgeClusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	r, ok := obj.(*BridgeCluster)
+	if !ok {
+		return fmt.Errorf("expected a BridgeCluster but got %T", obj)
+	}
+	bridgeclusterlog.Info("validate create", "name", r.Name)
+
+	return r.invalid(wh.validateCatalog(ctx, r.Spec))
+}
+
+// ValidateUpdate rejects changes to attributes fixed at creation, storage
+// reductions, downgrades of the major version and version changes while an
+// upgrade is in progress, and checks a changed plan or replica list against
+// the catalog. A failed or stalled upgrade leaves the Upgrading phase, so the
+// version may then be changed to try again.
+func (wh *bridgeClusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	old, ok := oldObj.(*BridgeCluster)
+	if !ok {
+		return fmt.Errorf("expected a BridgeCluster but got %T", oldObj)
+	}
+	r, ok := newObj.(*BridgeCluster)
+	if !ok {
+		return fmt.Errorf("expected a BridgeCluster but got %T", newObj)
+	}
+	bridgeclusterlog.Info("validate update", "name", r.Name)
+
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	if r.Spec.ClusterID != old.Spec.ClusterID {
+		errs = append(errs, field.Forbidden(spec.Child("cluster_id"), "cannot be changed after creation"))
+	}
+	if r.Spec.Name != old.Spec.Name {
+		errs = append(errs, field.Forbidden(spec.Child("name"), "cannot be changed after creation"))
+	}
+	if r.Spec.Provider != old.Spec.Provider {
+		errs = append(errs, field.Forbidden(spec.Child("provider"), "cannot be changed after creation"))
+	}
+	if r.Spec.Region != old.Spec.Region {
+		errs = append(errs, field.Forbidden(spec.Child("region"), "cannot be changed after creation"))
+	}
+	if r.Spec.StorageGB < old.Spec.StorageGB {
+		errs = append(errs, field.Invalid(spec.Child("storage"), r.Spec.StorageGB,
+			fmt.Sprintf("cannot be reduced from %d", old.Spec.StorageGB)))
+	}
+	if r.Spec.PGMajorVer < old.Spec.PGMajorVer {
+		errs = append(errs, field.Invalid(spec.Child("pg_major_version"), r.Spec.PGMajorVer,
+			fmt.Sprintf("cannot be downgraded from %d", old.Spec.PGMajorVer)))
+	} else if r.Spec.PGMajorVer != old.Spec.PGMajorVer && old.Status.Phase == PhaseUpgrading {
+		errs = append(errs, field.Forbidden(spec.Child("pg_major_version"),
+			"cannot be changed while an upgrade is in progress"))
+	}
+	if len(errs) == 0 && (r.Spec.Plan != old.Spec.Plan || !reflect.DeepEqual(r.Spec.Replicas, old.Spec.Replicas)) {
+		errs = wh.validateCatalog(ctx, r.Spec)
+	}
+
+	return r.invalid(errs)
+}
+
+// ValidateDelete allows all deletions, deletion protection is enforced by
+// the controller
+func (wh *bridgeClusterWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validateCatalog checks that the providers of spec and its replicas are
+// listed in the catalog and offer the plans and regions requested
+func (wh *bridgeClusterWebhook) validateCatalog(ctx context.Context, spec BridgeClusterSpec) field.ErrorList {
+	if wh.catalog == nil {
+		return nil
+	}
+
+	path := field.NewPath("spec")
+	errs, err := wh.validateOffering(ctx, spec.AccountRef, path, spec.Plan, spec.Provider, spec.Region)
+	if err != nil {
+		bridgeclusterlog.Error(err, "unable to fetch Crunchy Bridge catalog, skipping checks")
+		return nil
+	}
+	for i, rs := range spec.Replicas {
+		// Replicas default to the cluster's plan and location
+		plan, provider, region := rs.Plan, rs.Provider, rs.Region
+		if plan == "" {
+			plan = spec.Plan
+		}
+		if provider == "" {
+			provider = spec.Provider
+		}
+		if region == "" {
+			region = spec.Region
+		}
+		replicaErrs, err := wh.validateOffering(ctx, spec.AccountRef, path.Child("replicas").Index(i), plan, provider, region)
+		if err != nil {
+			bridgeclusterlog.Error(err, "unable to fetch Crunchy Bridge catalog, skipping checks")
+			return nil
+		}
+		errs = append(errs, replicaErrs...)
+	}
+	return errs
+}
+
+// validateOffering checks that provider is listed in the catalog and offers
+// plan and region, reporting problems against the fields below path
+func (wh *bridgeClusterWebhook) validateOffering(
+	ctx context.Context, accountRef string, path *field.Path, plan, provider, region string) (field.ErrorList, error) {
+
+	plans, regions, ok, err := wh.catalog.Offerings(ctx, accountRef, provider)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return field.ErrorList{field.Invalid(path.Child("provider"), provider,
+			"not offered by Crunchy Bridge")}, nil
+	}
+	var errs field.ErrorList
+	if !contains(plans, plan) {
+		errs = append(errs, field.NotSupported(path.Child("plan"), plan, plans))
+	}
+	if !contains(regions, region) {
+		errs = append(errs, field.NotSupported(path.Child("region"), region, regions))
+	}
+	return errs, nil
+}
+
+// invalid returns an Invalid error for r listing errs, nil if errs is empty
+func (r *BridgeCluster) invalid(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("BridgeCluster").GroupKind(), r.Name, errs)
+}
+
+func contains(list []string, s string) bool {
+	for _, str := range list {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
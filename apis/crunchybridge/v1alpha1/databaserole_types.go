@@ -33,6 +33,11 @@ type DatabaseRoleSpec struct {
 	// name if not provided
 	// +optional
 	RoleName string `json:"role_name"`
+	// names the BridgeAccount whose credentials manage this role, which
+	// should match that of the cluster.
+	// Defaults to the operator-wide credentials
+	// +optional
+	AccountRef string `json:"account_ref,omitempty"`
 }
 
 // DatabaseRoleStatus defines the observed state of DatabaseRole
@@ -45,8 +50,19 @@ type DatabaseRoleStatus struct {
 	Created string `json:"created_at"`
 	// represents the role provisioned for this request
 	RoleName string `json:"role_name"`
+	// represents the cluster on which the role was created
+	// +optional
+	ClusterID string `json:"cluster_id,omitempty"`
 	// represents the secret associated with this role
 	CredentialRef NamespacedName `json:"credential_ref"`
+	// represents the latest available observations of the role's state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// represents the .metadata.generation last acted upon by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observed_generation,omitempty"`
 }
 
 // Namespaced name is a light representation of a name in a namespace
@@ -67,6 +83,10 @@ type DatabaseRole struct {
 	Status DatabaseRoleStatus `json:"status,omitempty"`
 }
 
+func (in *DatabaseRole) GetStatusConditions() *[]metav1.Condition {
+	return &in.Status.Conditions
+}
+
 //+kubebuilder:object:root=true
 
 // DatabaseRoleList contains a list of DatabaseRole
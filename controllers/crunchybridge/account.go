@@ -0,0 +1,233 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crunchybridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/kubeadapter"
+)
+
+// AccountClients hands out Crunchy Bridge API clients for the account an
+// object refers to, falling back to the operator-wide client when no
+// account is referenced. Clients share login sessions through the
+// bridgeapi session cache, so accounts with the same credentials log in once
+type AccountClients struct {
+	// Reader fetches BridgeAccounts and their credential secrets, it should
+	// not be limited by the manager's cache
+	Reader client.Reader
+	// Default serves objects without an account reference. If unset, it is
+	// created from DefaultCredentials once those become available
+	Default bridgeapi.Interface
+	// DefaultCredentials provides the operator-wide API credentials, which
+	// may be missing at startup
+	DefaultCredentials bridgeapi.CredentialProvider
+	// APIURL is used for accounts which don't override it
+	APIURL *url.URL
+	// Options are applied to every client created for an account
+	Options []bridgeapi.ClientOption
+
+	mu      sync.Mutex
+	clients map[string]accountClient
+}
+
+// errNoDefaultAccount is returned for objects without an account_ref when
+// the operator has no credentials of its own
+var errNoDefaultAccount = errors.New("no default Crunchy Bridge account configured")
+
+// accountClient records the client built for a BridgeAccount generation
+type accountClient struct {
+	generation int64
+	client     bridgeapi.Interface
+}
+
+//+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=bridgeaccounts,verbs=get;list;watch
+
+// ClientFor returns the API client for the named BridgeAccount, or the
+// default client if accountRef is empty
+func (ac *AccountClients) ClientFor(ctx context.Context, accountRef string) (bridgeapi.Interface, error) {
+	if ac == nil {
+		return nil, errors.New("no Crunchy Bridge accounts configured")
+	}
+	if accountRef == "" {
+		return ac.defaultClient()
+	}
+
+	account := &crunchybridgev1alpha1.BridgeAccount{}
+	if err := ac.Reader.Get(ctx, client.ObjectKey{Name: accountRef}, account); err != nil {
+		return nil, fmt.Errorf("fetching BridgeAccount %s: %w", accountRef, err)
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	cached, ok := ac.clients[accountRef]
+	if ok && cached.generation == account.Generation {
+		return cached.client, nil
+	}
+
+	apiURL := ac.APIURL
+	if account.Spec.APIURL != "" {
+		parsed, err := url.Parse(strings.TrimRight(account.Spec.APIURL, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("parsing api_url of BridgeAccount %s: %w", accountRef, err)
+		}
+		apiURL = parsed
+	}
+
+	ksp := &kubeadapter.KubeSecretCredentialProvider{
+		Client:      ac.Reader,
+		Namespace:   account.Spec.CredentialsRef.Namespace,
+		Name:        account.Spec.CredentialsRef.Name,
+		KeyField:    account.Spec.KeyField,
+		SecretField: account.Spec.SecretField,
+	}
+	bc, err := bridgeapi.NewClient(apiURL, ksp, ac.Options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating client for BridgeAccount %s: %w", accountRef, err)
+	}
+
+	if ok {
+		// Superseded by the client for the new account spec
+		cached.client.Close()
+	}
+	if ac.clients == nil {
+		ac.clients = map[string]accountClient{}
+	}
+	ac.clients[accountRef] = accountClient{
+		generation: account.Generation,
+		client:     bc,
+	}
+	return bc, nil
+}
+
+// defaultClient returns the operator-wide client, creating it on first use
+// after the default credentials have appeared
+func (ac *AccountClients) defaultClient() (bridgeapi.Interface, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.Default != nil {
+		return ac.Default, nil
+	}
+	if ac.DefaultCredentials == nil {
+		return nil, errNoDefaultAccount
+	}
+
+	bc, err := bridgeapi.NewClient(ac.APIURL, ac.DefaultCredentials, ac.Options...)
+	if err != nil {
+		return nil, err
+	}
+	ac.Default = bc
+	return bc, nil
+}
+
+// DefaultTeamID returns the team clusters of the account are created in
+// when none is specified, for the BridgeCluster webhooks
+func (ac *AccountClients) DefaultTeamID(ctx context.Context, accountRef string) (string, error) {
+	bc, err := ac.ClientFor(ctx, accountRef)
+	if err != nil {
+		return "", err
+	}
+	return bc.DefaultTeamID(ctx)
+}
+
+// Offerings returns the plans and regions the account may use from
+// provider, for the BridgeCluster webhooks
+func (ac *AccountClients) Offerings(ctx context.Context, accountRef, provider string) (plans, regions []string, ok bool, err error) {
+	bc, err := ac.ClientFor(ctx, accountRef)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	list, err := bc.ListProviders(ctx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	for _, p := range list.Providers {
+		if p.ID != provider {
+			continue
+		}
+		for _, plan := range p.Plans {
+			plans = append(plans, plan.ID)
+		}
+		for _, region := range p.Regions {
+			regions = append(regions, region.ID)
+		}
+		return plans, regions, true, nil
+	}
+	return nil, nil, false, nil
+}
+
+var _ crunchybridgev1alpha1.BridgeCatalog = (*AccountClients)(nil)
+
+// Reconcile closes the client of a deleted BridgeAccount, whose login
+// session would otherwise be kept refreshing
+func (ac *AccountClients) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	account := &crunchybridgev1alpha1.BridgeAccount{}
+	if err := ac.Reader.Get(ctx, client.ObjectKey{Name: req.Name}, account); !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	ac.mu.Lock()
+	cached, ok := ac.clients[req.Name]
+	delete(ac.clients, req.Name)
+	ac.mu.Unlock()
+
+	if ok {
+		log.FromContext(ctx).Info("releasing client of deleted BridgeAccount", "account", req.Name)
+		cached.client.Close()
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager watches BridgeAccounts for the clients of deleted
+// accounts to be released
+func (ac *AccountClients) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("bridgeaccount").
+		For(&crunchybridgev1alpha1.BridgeAccount{}).
+		Complete(ac)
+}
+
+// LoginCheck is a healthz.Checker reporting the login state of the default
+// account, passing when no default credentials are configured
+func (ac *AccountClients) LoginCheck(_ *http.Request) error {
+	bc, err := ac.defaultClient()
+	if errors.Is(err, errNoDefaultAccount) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if state := bc.GetLoginState(); state != bridgeapi.LoginActive {
+		return fmt.Errorf("Crunchy Bridge login %s", state)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crunchybridge
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/reconciletest"
+)
+
+func TestAccountClients(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	accounts := &AccountClients{Reader: env.Client, Default: env.Bridge, APIURL: env.Server.APIURL()}
+	teamID := env.Server.AddAccount("tenant", "tenant-secret")
+	env.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: "tenant-creds"},
+		Data:       map[string][]byte{"key": []byte("tenant"), "secret": []byte("tenant-secret")},
+	})
+	account := &crunchybridgev1alpha1.BridgeAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+		Spec: crunchybridgev1alpha1.BridgeAccountSpec{
+			CredentialsRef: crunchybridgev1alpha1.NamespacedName{Namespace: reconciletest.Namespace, Name: "tenant-creds"},
+			KeyField:       "key",
+			SecretField:    "secret",
+		},
+	}
+	env.Create(account)
+
+	if got, err := accounts.DefaultTeamID(env.Ctx, "tenant"); err != nil || got != teamID {
+		t.Fatalf("DefaultTeamID = %q, %v; want %q", got, err, teamID)
+	}
+	bc, _ := accounts.ClientFor(env.Ctx, "tenant")
+	if again, _ := accounts.ClientFor(env.Ctx, "tenant"); again != bc {
+		t.Error("client of an unchanged account not reused")
+	}
+
+	// Clients of existing accounts are kept
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "tenant"}}
+	if _, err := accounts.Reconcile(env.Ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := accounts.clients["tenant"]; !ok {
+		t.Fatal("client of an existing account released")
+	}
+
+	env.Delete(account)
+	if _, err := accounts.Reconcile(env.Ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := accounts.clients["tenant"]; ok {
+		t.Error("client of a deleted account kept")
+	}
+	if _, err := accounts.ClientFor(env.Ctx, "tenant"); err == nil {
+		t.Error("ClientFor succeeded for a deleted account")
+	}
+}
+
+func TestAccountLoginCheck(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+
+	// Installs without operator-wide credentials are ready
+	accounts := &AccountClients{Reader: env.Client, APIURL: env.Server.APIURL()}
+	if err := accounts.LoginCheck(nil); err != nil {
+		t.Errorf("LoginCheck without default credentials = %v", err)
+	}
+
+	accounts.Default = env.Bridge
+	if _, err := env.Bridge.DefaultTeamID(env.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := accounts.LoginCheck(nil); err != nil {
+		t.Errorf("LoginCheck while logged in = %v", err)
+	}
+
+	rejected, err := bridgeapi.NewClient(env.Server.APIURL(), bridgeapi.LoginCred{Key: reconciletest.Key, Secret: "wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rejected.Close()
+	rejected.DefaultTeamID(env.Ctx)
+	accounts.Default = rejected
+	if err := accounts.LoginCheck(nil); err == nil {
+		t.Error("LoginCheck with rejected credentials passed")
+	}
+}
@@ -21,18 +21,29 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
 	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgepoll"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/lifecycle"
 )
 
 const (
@@ -42,14 +53,26 @@ const (
 // BridgeClusterReconciler reconciles a BridgeCluster object
 type BridgeClusterReconciler struct {
 	client.Client
-	Scheme       *runtime.Scheme
-	BridgeClient *bridgeapi.Client
-	WatchInt     time.Duration
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Accounts *AccountClients
+	// APIReader reads objects bypassing the manager cache, the manager's
+	// API reader is used if unset
+	APIReader client.Reader
+	// Pollers watch cluster state on behalf of the reconciler, one per
+	// account. A registry given here may be shared with other reconcilers
+	// and is started by the caller, one with default intervals is created
+	// and started if unset
+	Pollers *bridgepoll.Registry
+
+	events chan event.GenericEvent
 }
 
 //+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=bridgeclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=bridgeclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=bridgeclusters/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -59,12 +82,6 @@ type BridgeClusterReconciler struct {
 func (r *BridgeClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	if r.BridgeClient == nil {
-		err := errors.New("Uninitialized client")
-		logger.Error(err, "No CrunchyBridge client configured")
-		return ctrl.Result{}, err
-	}
-
 	clusterObj := &crunchybridgev1alpha1.BridgeCluster{}
 	if err := r.Get(ctx, req.NamespacedName, clusterObj); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -75,16 +92,73 @@ func (r *BridgeClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	if !clusterObj.DeletionTimestamp.IsZero() && forceRemovable(clusterObj) {
+		// Checked before any API access, which may be what is failing
+		return r.forceRemove(ctx, clusterObj)
+	}
+
+	bridgeClient, err := r.Accounts.ClientFor(ctx, clusterObj.Spec.AccountRef)
+	if err != nil {
+		return r.recordError(ctx, clusterObj, err)
+	}
+	poller := r.pollerFor(clusterObj.Spec.AccountRef)
+
 	if clusterObj.DeletionTimestamp != nil && !clusterObj.DeletionTimestamp.IsZero() {
 		// Cluster deletion request / process finalizer
 		if listContains(clusterObj.Finalizers, bcFinalizer) {
-			if id := clusterObj.Status.Cluster.ID; id != "" {
+			if clusterObj.Annotations[crunchybridgev1alpha1.AnnotationDeletionProtection] == "true" {
+				// Removing the annotation triggers another pass
+				msg := fmt.Sprintf("deletion blocked by %s annotation",
+					crunchybridgev1alpha1.AnnotationDeletionProtection)
+				logger.Info(msg, "id", clusterObj.Status.Cluster.ID)
+				if clusterObj.Status.Message != msg {
+					clusterObj.Status.Message = msg
+					clusterObj.Status.Updated = time.Now().Format(time.RFC3339)
+					setStatusCondition(clusterObj, ConditionReady, metav1.ConditionFalse, ReasonDeletionProtected, msg)
+					if err := r.Status().Update(ctx, clusterObj); err != nil {
+						return ctrl.Result{}, err
+					}
+					r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonDeletionProtected, msg)
+				}
+				return ctrl.Result{}, nil
+			}
+
+			id := clusterObj.Status.Cluster.ID
+			switch {
+			case id == "":
+				// Nothing was created, nothing to clean up
+			case clusterObj.Spec.DeletionPolicy == crunchybridgev1alpha1.DeletionPolicyRetain:
+				logger.Info("retaining cluster per deletion policy", "id", id)
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonRetained,
+					"Retained cluster %s per deletion policy", id)
+			case clusterObj.Status.Phase != crunchybridgev1alpha1.PhaseDeleting:
 				logger.Info("deleting cluster", "id", id)
-				if err := r.BridgeClient.DeleteCluster(id); err != nil {
-					return ctrl.Result{}, err
+				err := bridgeClient.DeleteCluster(ctx, id)
+				switch {
+				case errors.Is(err, bridgeapi.ErrorNotFound):
+					logger.Info("cluster already removed", "id", id)
+					r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonDeleted, "Cluster %s already removed", id)
+				case err != nil:
+					return r.recordError(ctx, clusterObj, err)
+				default:
+					// Bridge tears the cluster down in the background, the
+					// finalizer stays until it is gone
+					clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseDeleting
+					clusterObj.Status.DeletionStarted = time.Now().Format(time.RFC3339)
+					clusterObj.Status.Message = fmt.Sprintf("requested removal of cluster %s", id)
+					if err := r.updateStatus(ctx, clusterObj); err != nil {
+						return ctrl.Result{}, err
+					}
+					r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonDeleting, "Deleting cluster %s", id)
+					poller.Track(r.events, clusterObj, id, clusterObj.Spec.Name, true)
+					return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, nil
+				}
+			default:
+				if result, gone, err := r.waitForRemoval(ctx, bridgeClient, poller, clusterObj); !gone {
+					return result, err
 				}
-				logger.Info("cluster deleted", "id", id)
 			}
+			poller.Untrack(r.events, clusterObj)
 			controllerutil.RemoveFinalizer(clusterObj, bcFinalizer)
 			if err := r.Update(ctx, clusterObj); err != nil {
 				return ctrl.Result{}, err
@@ -104,47 +178,117 @@ func (r *BridgeClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			// Set pending phase after so any errors in setting finalizer
 			// don't advance state
 			clusterObj.Status.Phase = crunchybridgev1alpha1.PhasePending
-			if err := r.Status().Update(ctx, clusterObj); err != nil {
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.Recorder.Event(clusterObj, corev1.EventTypeNormal, ReasonPending, "Cluster pending creation")
 
 		case crunchybridgev1alpha1.PhasePending:
-			req, err := r.createFromSpec(clusterObj.Spec)
+			if cid := clusterObj.Spec.ClusterID; cid != "" {
+				detC, err := bridgeClient.ClusterDetail(ctx, cid)
+				if err != nil {
+					return r.recordError(ctx, clusterObj, err)
+				}
+				if err := verifyAdoptable(clusterObj.Spec, detC); err != nil {
+					logger.Error(err, "cluster cannot be adopted", "id", cid)
+					r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonSpecMismatch, err.Error())
+					setStatusCondition(clusterObj, ConditionReady, metav1.ConditionFalse, ReasonSpecMismatch, err.Error())
+					if statusErr := r.Status().Update(ctx, clusterObj); statusErr != nil {
+						logger.Error(statusErr, "Error in updating BridgeCluster status")
+					}
+					return ctrl.Result{}, err
+				}
+
+				if _, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status); err != nil {
+					return r.recordError(ctx, clusterObj, err)
+				}
+
+				// Adopted clusters may still be provisioning, let the
+				// Creating phase watch them through to ready
+				clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseCreating
+				clusterObj.Status.ProvisioningStarted = time.Now().Format(time.RFC3339)
+				if detC.State == string(bridgeapi.StateReady) {
+					clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseReady
+				}
+				logger.Info("cluster adopted", "id", cid, "name", detC.Name)
+
+				if err := r.updateStatus(ctx, clusterObj); err != nil {
+					return ctrl.Result{}, err
+				}
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonAdopted,
+					"Adopted cluster %s in state %s", cid, detC.State)
+				poller.Track(r.events, clusterObj, detC.ID, detC.Name,
+					clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseCreating)
+				return ctrl.Result{}, nil
+			}
+
+			req, err := r.createFromSpec(ctx, bridgeClient, clusterObj.Spec)
 			if err != nil {
-				return ctrl.Result{}, err
+				return r.recordError(ctx, clusterObj, err)
 			}
 
 			logger.Info("cluster create requested", "name", clusterObj.Spec.Name)
-			if err := r.BridgeClient.CreateCluster(req); err != nil {
-				return ctrl.Result{}, err
+			clusterObj.Status.ProvisioningAttempts++
+			detC, err := bridgeClient.CreateCluster(ctx, req)
+			if err != nil {
+				// Bridge won't accept the same request later, conflicts on
+				// the name are retried as they may clear once an earlier
+				// cluster is gone
+				if errors.Is(err, bridgeapi.ErrorBadRequest) {
+					return r.failProvisioning(ctx, poller, clusterObj, crunchybridgev1alpha1.PhaseFailed,
+						fmt.Sprintf("create request rejected: %v", err))
+				}
+				return r.recordError(ctx, clusterObj, err)
 			}
 
-			// Assuming the request was sent, update phase
+			// Following the new cluster by ID keeps an earlier one of the
+			// same name, such as one still being destroyed, from being
+			// mistaken for it
+			clusterObj.Status.Cluster.ID = detC.ID
 			clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseCreating
-			clusterObj.Status.Updated = time.Now().Format(time.RFC3339)
-			if err := r.Status().Update(ctx, clusterObj); err != nil {
+			clusterObj.Status.ProvisioningStarted = time.Now().Format(time.RFC3339)
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonCreateRequested,
+				"Requested creation of cluster %s", clusterObj.Spec.Name)
+			poller.Track(r.events, clusterObj, detC.ID, clusterObj.Spec.Name, true)
 
 		case crunchybridgev1alpha1.PhaseCreating:
-			var detC bridgeapi.ClusterDetail
-			if cid := clusterObj.Status.Cluster.ID; cid == "" {
-				c, err := r.BridgeClient.ClusterByName(clusterObj.Spec.Name)
-				if err != nil {
-					return ctrl.Result{}, err
-				}
-				detC = c
-			} else {
-				c, err := r.BridgeClient.ClusterDetail(cid)
-				if err != nil {
-					return ctrl.Result{}, err
+			cid := clusterObj.Status.Cluster.ID
+			detC, found, err := poller.Lookup(cid, clusterObj.Spec.Name)
+			if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			}
+			// The poller sends the object back once the cluster is listed
+			poller.Track(r.events, clusterObj, cid, clusterObj.Spec.Name, true)
+			remaining := lifecycle.Remaining(clusterObj.Status.ProvisioningStarted, provisioningTimeout(clusterObj))
+			if !found {
+				if remaining <= 0 {
+					return r.failProvisioning(ctx, poller, clusterObj, crunchybridgev1alpha1.PhaseStalled,
+						fmt.Sprintf("cluster %s not listed within %s", clusterObj.Spec.Name, provisioningTimeout(clusterObj)))
 				}
-				detC = c
+				return ctrl.Result{RequeueAfter: remaining}, nil
 			}
-			logger.Info("cluster creating", "name", clusterObj.Spec.Name)
+			logger.Info("cluster creating", "name", clusterObj.Spec.Name, "state", detC.State)
 
-			if err := r.updateStatusFromDetail(detC, &clusterObj.Status); err != nil {
-				return ctrl.Result{}, err
+			if bridgeapi.ClusterState(detC.State).ProvisioningFailed() {
+				clusterObj.Status.Cluster.ID = detC.ID
+				return r.failProvisioning(ctx, poller, clusterObj, crunchybridgev1alpha1.PhaseFailed,
+					fmt.Sprintf("cluster %s reported state %q while provisioning", detC.ID, detC.State))
+			}
+			if detC.State != string(bridgeapi.StateReady) && remaining <= 0 {
+				clusterObj.Status.Cluster.ID = detC.ID
+				return r.failProvisioning(ctx, poller, clusterObj, crunchybridgev1alpha1.PhaseStalled,
+					fmt.Sprintf("cluster %s still in state %q after %s", detC.ID, detC.State, provisioningTimeout(clusterObj)))
+			}
+
+			if _, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status); err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			}
+			if clusterObj.Status.ProvisioningStarted == "" {
+				// Objects created before provisioning was timed
+				clusterObj.Status.ProvisioningStarted = time.Now().Format(time.RFC3339)
 			}
 
 			if readyNow := (detC.State == string(bridgeapi.StateReady)); readyNow {
@@ -152,14 +296,292 @@ func (r *BridgeClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 				logger.Info("cluster created", "name", clusterObj.Spec.Name)
 			}
 
-			clusterObj.Status.Updated = time.Now().Format(time.RFC3339)
-			if err := r.Status().Update(ctx, clusterObj); err != nil {
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			if clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseReady {
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonCreated, "Cluster %s is ready", detC.ID)
+			}
+			poller.Track(r.events, clusterObj, detC.ID, detC.Name,
+				clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseCreating)
+			if clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseCreating {
+				// The poller only reports changes, come back to check the
+				// timeout if the cluster sits in one state
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+
+		case crunchybridgev1alpha1.PhaseFailed, crunchybridgev1alpha1.PhaseStalled:
+			if upgradeFailed(clusterObj) {
+				return r.followFailedUpgrade(ctx, bridgeClient, poller, clusterObj)
+			}
+			cid := clusterObj.Status.Cluster.ID
+			// A cluster being removed for a retry is seen through first
+			removing := cid != "" && clusterObj.Status.DeletionStarted != ""
+			if clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseStalled && !removing {
+				// Bridge may yet finish provisioning a stalled cluster
+				detC, found, err := poller.Lookup(cid, clusterObj.Spec.Name)
+				if err != nil {
+					return r.recordError(ctx, clusterObj, err)
+				}
+				if found && bridgeapi.ClusterState(detC.State).ProvisioningFailed() {
+					clusterObj.Status.Cluster.ID = detC.ID
+					return r.failProvisioning(ctx, poller, clusterObj, crunchybridgev1alpha1.PhaseFailed,
+						fmt.Sprintf("cluster %s reported state %q while provisioning", detC.ID, detC.State))
+				}
+				if found && detC.State == string(bridgeapi.StateReady) {
+					if _, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status); err != nil {
+						return r.recordError(ctx, clusterObj, err)
+					}
+					clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseReady
+					clusterObj.Status.Message = ""
+					logger.Info("stalled cluster became ready", "name", clusterObj.Spec.Name)
+					if err := r.updateStatus(ctx, clusterObj); err != nil {
+						return ctrl.Result{}, err
+					}
+					r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonCreated, "Cluster %s is ready", detC.ID)
+					poller.Track(r.events, clusterObj, detC.ID, detC.Name, false)
+					return ctrl.Result{}, nil
+				}
+			}
+
+			if !removing {
+				// Retrying an adopted cluster would replace a cluster the
+				// operator didn't create
+				respecified := clusterObj.Generation != clusterObj.Status.ObservedGeneration
+				retry := respecified ||
+					clusterObj.Spec.ProvisioningFailurePolicy == crunchybridgev1alpha1.ProvisioningFailurePolicyRetry
+				if !retry || clusterObj.Spec.ClusterID != "" {
+					return ctrl.Result{}, nil
+				}
+				// Failing without a cluster means Bridge rejected the create
+				// request, which only a spec change can fix
+				rejected := clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseFailed && cid == ""
+				if rejected && !respecified {
+					return ctrl.Result{}, nil
+				}
+				if !respecified {
+					wait := lifecycle.RetryWait(clusterObj.Status.ProvisioningAttempts, clusterObj.Status.Updated)
+					if wait > 0 {
+						return ctrl.Result{RequeueAfter: wait}, nil
+					}
+				}
+			}
+
+			if cid != "" {
+				// The old cluster keeps its name, and stays listed, until
+				// Bridge has destroyed it. Creating again before then would
+				// conflict or find the old cluster.
+				if !removing {
+					logger.Info("deleting cluster for provisioning retry", "id", cid)
+					if err := bridgeClient.DeleteCluster(ctx, cid); err != nil && !errors.Is(err, bridgeapi.ErrorNotFound) {
+						return r.recordError(ctx, clusterObj, err)
+					}
+					clusterObj.Status.DeletionStarted = time.Now().Format(time.RFC3339)
+					clusterObj.Status.Message = fmt.Sprintf("removing cluster %s to retry provisioning", cid)
+					if err := r.updateStatus(ctx, clusterObj); err != nil {
+						return ctrl.Result{}, err
+					}
+					r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonDeleting,
+						"Deleting cluster %s to retry provisioning", cid)
+					poller.Track(r.events, clusterObj, cid, clusterObj.Spec.Name, true)
+					return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, nil
+				}
+				if _, err := bridgeClient.ClusterDetail(ctx, cid); err == nil {
+					return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, nil
+				} else if !errors.Is(err, bridgeapi.ErrorNotFound) {
+					return r.recordError(ctx, clusterObj, err)
+				}
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonDeleted,
+					"Deleted cluster %s to retry provisioning", cid)
+			}
+			poller.Untrack(r.events, clusterObj)
+
+			clusterObj.Status.Cluster = crunchybridgev1alpha1.ClusterStatus{}
+			clusterObj.Status.Connect = crunchybridgev1alpha1.Connection{}
+			clusterObj.Status.Replicas = nil
+			clusterObj.Status.Message = ""
+			clusterObj.Status.DeletionStarted = ""
+			clusterObj.Status.Phase = crunchybridgev1alpha1.PhasePending
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
 				return ctrl.Result{}, err
 			}
-			return ctrl.Result{Requeue: true, RequeueAfter: r.WatchInt}, nil
+			r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonRetrying,
+				"Retrying provisioning after %d attempts", clusterObj.Status.ProvisioningAttempts)
 
 		case crunchybridgev1alpha1.PhaseReady:
-			// TODO: Monitor changes, change state machine (phoenix)
+			detC, found, err := r.lookupCluster(ctx, bridgeClient, poller, clusterObj)
+			if errors.Is(err, bridgeapi.ErrorNotFound) {
+				return r.markLost(ctx, poller, clusterObj)
+			} else if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			} else if !found {
+				return ctrl.Result{}, nil
+			}
+
+			role, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status)
+			if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			}
+			setDegradedCondition(clusterObj, detC)
+			if err := r.writeConnectionSecret(ctx, clusterObj, role); err != nil {
+				return ctrl.Result{}, err
+			}
+			replicasPending, err := r.reconcileReplicas(ctx, bridgeClient, clusterObj, detC)
+			if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			}
+
+			r.refuseStorageShrink(clusterObj, detC)
+			if ur, changed := updateFromSpec(clusterObj.Spec, detC); changed {
+				logger.Info("cluster update requested", "name", clusterObj.Spec.Name, "request", ur)
+				if err := bridgeClient.UpdateCluster(ctx, detC.ID, ur); err != nil {
+					return r.recordError(ctx, clusterObj, err)
+				}
+				clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseUpdating
+				poller.Refresh()
+			} else if err := r.upgradeFromSpec(ctx, bridgeClient, clusterObj, detC); err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			} else if clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseUpgrading {
+				poller.Refresh()
+			}
+
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			switch clusterObj.Status.Phase {
+			case crunchybridgev1alpha1.PhaseUpdating:
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonUpdateRequested,
+					"Requested update of cluster %s", detC.ID)
+			case crunchybridgev1alpha1.PhaseUpgrading:
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonUpgradeRequested,
+					"Requested upgrade of cluster %s to PostgreSQL %d", detC.ID, clusterObj.Spec.PGMajorVer)
+			}
+			// Replica changes are only noticed through the primary's detail,
+			// keep it polled closely until they settle
+			poller.Track(r.events, clusterObj, detC.ID, detC.Name,
+				clusterObj.Status.Phase != crunchybridgev1alpha1.PhaseReady || replicasPending)
+			// Connection roles aren't polled, a connection secret follows
+			// password changes made outside the operator on resync
+			if clusterObj.Spec.ConnectionSecretRef != nil {
+				return ctrl.Result{RequeueAfter: r.Pollers.MaxIntervalOrDefault()}, nil
+			}
+
+		case crunchybridgev1alpha1.PhaseUpdating:
+			detC, found, err := r.lookupCluster(ctx, bridgeClient, poller, clusterObj)
+			if errors.Is(err, bridgeapi.ErrorNotFound) {
+				return r.markLost(ctx, poller, clusterObj)
+			} else if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			} else if !found {
+				return ctrl.Result{}, nil
+			}
+			logger.Info("cluster updating", "name", clusterObj.Spec.Name)
+
+			role, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status)
+			if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			}
+			if err := r.writeConnectionSecret(ctx, clusterObj, role); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Bridge may report ready before the change is applied, so the
+			// live shape must also match the spec before leaving Updating
+			_, pending := updateFromSpec(clusterObj.Spec, detC)
+			if readyNow := (detC.State == string(bridgeapi.StateReady)); readyNow && !pending {
+				clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseReady
+				logger.Info("cluster updated", "name", clusterObj.Spec.Name)
+			}
+
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			if clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseReady {
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonUpdated, "Cluster %s updated", detC.ID)
+			}
+			poller.Track(r.events, clusterObj, detC.ID, detC.Name,
+				clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseUpdating)
+
+		case crunchybridgev1alpha1.PhaseUpgrading:
+			detC, found, err := r.lookupCluster(ctx, bridgeClient, poller, clusterObj)
+			if errors.Is(err, bridgeapi.ErrorNotFound) {
+				return r.markLost(ctx, poller, clusterObj)
+			} else if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			} else if !found {
+				return ctrl.Result{}, nil
+			}
+			logger.Info("cluster upgrading", "name", clusterObj.Spec.Name, "state", detC.State)
+
+			role, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status)
+			if err != nil {
+				return r.recordError(ctx, clusterObj, err)
+			}
+			if err := r.writeConnectionSecret(ctx, clusterObj, role); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// As with updates, the cluster may report ready before the
+			// upgrade has begun
+			upgraded := detC.State == string(bridgeapi.StateReady) &&
+				detC.PGMajorVersion >= clusterObj.Spec.PGMajorVer
+			if clusterObj.Status.UpgradeStarted == "" {
+				// Objects upgrading before upgrades were timed
+				clusterObj.Status.UpgradeStarted = time.Now().Format(time.RFC3339)
+			}
+			remaining := lifecycle.Remaining(clusterObj.Status.UpgradeStarted, provisioningTimeout(clusterObj))
+			if upgraded {
+				clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseReady
+				clusterObj.Status.Message = ""
+				clusterObj.Status.UpgradeStarted = ""
+				apimeta.RemoveStatusCondition(&clusterObj.Status.Conditions, ConditionUpgradePending)
+				setDegradedCondition(clusterObj, detC)
+				logger.Info("cluster upgraded", "name", clusterObj.Spec.Name, "version", detC.PGMajorVersion)
+			} else if bridgeapi.ClusterState(detC.State).ProvisioningFailed() {
+				return r.failUpgrade(ctx, poller, clusterObj, detC, crunchybridgev1alpha1.PhaseFailed,
+					fmt.Sprintf("cluster %s reported state %q while upgrading to PostgreSQL %d",
+						detC.ID, detC.State, clusterObj.Spec.PGMajorVer))
+			} else if remaining <= 0 {
+				return r.failUpgrade(ctx, poller, clusterObj, detC, crunchybridgev1alpha1.PhaseStalled,
+					fmt.Sprintf("cluster %s not upgraded to PostgreSQL %d after %s",
+						detC.ID, clusterObj.Spec.PGMajorVer, provisioningTimeout(clusterObj)))
+			}
+
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			if upgraded {
+				r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonUpgraded,
+					"Cluster %s upgraded to PostgreSQL %d", detC.ID, detC.PGMajorVersion)
+			}
+			poller.Track(r.events, clusterObj, detC.ID, detC.Name, !upgraded)
+			if !upgraded {
+				// The poller only reports changes, come back to check the
+				// timeout if the cluster sits in one state
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+
+		case crunchybridgev1alpha1.PhaseLost:
+			// Recreating an adopted cluster would replace a cluster the
+			// operator didn't create
+			if clusterObj.Spec.RecoveryPolicy != crunchybridgev1alpha1.RecoveryPolicyRecreate ||
+				clusterObj.Spec.ClusterID != "" {
+				return ctrl.Result{}, nil
+			}
+
+			lostID := clusterObj.Status.Cluster.ID
+			logger.Info("recreating lost cluster", "id", lostID, "name", clusterObj.Spec.Name)
+			clusterObj.Status.Cluster = crunchybridgev1alpha1.ClusterStatus{}
+			clusterObj.Status.Connect = crunchybridgev1alpha1.Connection{}
+			clusterObj.Status.Replicas = nil
+			clusterObj.Status.Message = ""
+			clusterObj.Status.ProvisioningAttempts = 0
+			clusterObj.Status.Phase = crunchybridgev1alpha1.PhasePending
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonRecreating,
+				"Recreating cluster %s per recovery policy", lostID)
 
 		default:
 			return ctrl.Result{}, fmt.Errorf("unrecognized phase: %s", clusterObj.Status.Phase)
@@ -170,10 +592,527 @@ func (r *BridgeClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	return ctrl.Result{}, nil
 }
 
+// updateStatus writes back the status of clusterObj, setting the conditions
+// which follow from its phase and clearing any earlier API error. Nothing is
+// written when the status is as stored apart from the Updated timestamp,
+// each write comes back as a watch event and another pass.
+func (r *BridgeClusterReconciler) updateStatus(ctx context.Context, clusterObj *crunchybridgev1alpha1.BridgeCluster) error {
+	setPhaseConditions(clusterObj, clusterObj.Status.Phase, clusterObj.Status.Message)
+	setErrorConditions(clusterObj, nil)
+	clusterObj.Status.ObservedGeneration = clusterObj.Generation
+
+	stored := &crunchybridgev1alpha1.BridgeCluster{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(clusterObj), stored); err == nil &&
+		stored.ResourceVersion == clusterObj.ResourceVersion {
+		stored.Status.Updated = clusterObj.Status.Updated
+		if equality.Semantic.DeepEqual(stored.Status, clusterObj.Status) {
+			return nil
+		}
+	}
+	clusterObj.Status.Updated = time.Now().Format(time.RFC3339)
+	return r.Status().Update(ctx, clusterObj)
+}
+
+// recordError reflects a Crunchy Bridge API error in the status conditions
+// and events of clusterObj and passes it back for the request to be retried
+func (r *BridgeClusterReconciler) recordError(ctx context.Context, clusterObj *crunchybridgev1alpha1.BridgeCluster, err error) (ctrl.Result, error) {
+	r.Recorder.Event(clusterObj, corev1.EventTypeWarning, errorReason(err), err.Error())
+	setErrorConditions(clusterObj, err)
+	if statusErr := r.Status().Update(ctx, clusterObj); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "Error in updating BridgeCluster status")
+	}
+	return ctrl.Result{}, err
+}
+
+// markLost moves clusterObj, whose cluster no longer exists in Crunchy
+// Bridge, to the Lost phase. The recovery policy is applied in a later pass.
+func (r *BridgeClusterReconciler) markLost(
+	ctx context.Context,
+	poller *bridgepoll.Poller,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster) (ctrl.Result, error) {
+
+	id := clusterObj.Status.Cluster.ID
+	msg := fmt.Sprintf("cluster %s no longer exists in Crunchy Bridge", id)
+	log.FromContext(ctx).Info("cluster lost", "id", id, "name", clusterObj.Spec.Name)
+
+	clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseLost
+	clusterObj.Status.Message = msg
+	if err := r.updateStatus(ctx, clusterObj); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonLost, msg)
+	poller.Untrack(r.events, clusterObj)
+	return ctrl.Result{}, nil
+}
+
+// waitForRemoval checks whether the cluster of clusterObj, being deleted, is
+// gone from Crunchy Bridge. Until it is, progress is reported in the status
+// message and the result schedules the next check.
+func (r *BridgeClusterReconciler) waitForRemoval(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	poller *bridgepoll.Poller,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster) (ctrl.Result, bool, error) {
+
+	logger := log.FromContext(ctx)
+	id := clusterObj.Status.Cluster.ID
+
+	removal, err := lifecycle.CheckRemoval(ctx, bridgeClient, id, clusterObj.Status.DeletionStarted,
+		deletionTimeout(clusterObj), crunchybridgev1alpha1.AnnotationForceRemove)
+	if err != nil {
+		result, err := r.recordError(ctx, clusterObj, err)
+		return result, false, err
+	}
+	if removal.Gone {
+		logger.Info("cluster deleted", "id", id)
+		r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonDeleted, "Deleted cluster %s", id)
+		return ctrl.Result{}, true, nil
+	}
+
+	if clusterObj.Status.Message != removal.Message {
+		logger.Info("cluster deleting", "id", id, "stalled", removal.Stalled)
+		clusterObj.Status.Message = removal.Message
+		if err := r.updateStatus(ctx, clusterObj); err != nil {
+			return ctrl.Result{}, false, err
+		}
+		if removal.Stalled {
+			r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonDeletionStalled, removal.Message)
+		}
+	}
+	poller.Track(r.events, clusterObj, id, clusterObj.Spec.Name, true)
+	return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, false, nil
+}
+
+// forceRemovable reports whether the finalizer of clusterObj may be
+// released without confirming the cluster was removed. Deletion protection
+// still takes precedence.
+func forceRemovable(clusterObj *crunchybridgev1alpha1.BridgeCluster) bool {
+	return listContains(clusterObj.Finalizers, bcFinalizer) &&
+		clusterObj.Annotations[crunchybridgev1alpha1.AnnotationForceRemove] == "true" &&
+		clusterObj.Annotations[crunchybridgev1alpha1.AnnotationDeletionProtection] != "true"
+}
+
+// forceRemove releases the finalizer of clusterObj, leaving whatever remains
+// of its cluster in Crunchy Bridge
+func (r *BridgeClusterReconciler) forceRemove(ctx context.Context, clusterObj *crunchybridgev1alpha1.BridgeCluster) (ctrl.Result, error) {
+	r.pollerFor(clusterObj.Spec.AccountRef).Untrack(r.events, clusterObj)
+	return ctrl.Result{}, lifecycle.ForceRemove(ctx, r.Client, r.Recorder, clusterObj, bcFinalizer,
+		clusterObj.Status.Cluster.ID, ReasonForceRemoved, crunchybridgev1alpha1.AnnotationForceRemove)
+}
+
+// deletionTimeout returns how long the cluster of clusterObj may take to
+// be removed
+func deletionTimeout(clusterObj *crunchybridgev1alpha1.BridgeCluster) time.Duration {
+	if t := clusterObj.Spec.DeletionTimeout; t != nil && t.Duration > 0 {
+		return t.Duration
+	}
+	return lifecycle.DefaultDeletionTimeout
+}
+
+// failProvisioning moves clusterObj to the Failed or Stalled phase with the
+// explanation in message. The phase decides on any retry in a later pass.
+func (r *BridgeClusterReconciler) failProvisioning(
+	ctx context.Context,
+	poller *bridgepoll.Poller,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster,
+	phase, message string) (ctrl.Result, error) {
+
+	log.FromContext(ctx).Info("cluster provisioning "+strings.ToLower(phase),
+		"name", clusterObj.Spec.Name, "message", message)
+	clusterObj.Status.Phase = phase
+	clusterObj.Status.Message = message
+	if err := r.updateStatus(ctx, clusterObj); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	stalled := phase == crunchybridgev1alpha1.PhaseStalled
+	lifecycle.FollowFailure(poller, r.events, clusterObj, clusterObj.Status.Cluster.ID, clusterObj.Spec.Name, stalled)
+	reason := ReasonFailed
+	if stalled {
+		reason = ReasonStalled
+	}
+	r.Recorder.Event(clusterObj, corev1.EventTypeWarning, reason, message)
+	return ctrl.Result{}, nil
+}
+
+// upgradeFailed reports whether clusterObj is Failed or Stalled because its
+// last upgrade failed, rather than its provisioning
+func upgradeFailed(clusterObj *crunchybridgev1alpha1.BridgeCluster) bool {
+	cond := apimeta.FindStatusCondition(clusterObj.Status.Conditions, ConditionUpgradePending)
+	return cond != nil && cond.Reason == ReasonUpgradeFailed
+}
+
+// failUpgrade moves clusterObj, whose upgrade failed or timed out on the
+// cluster described by det, to the Failed or Stalled phase with the
+// explanation in message and the UpgradePending condition
+func (r *BridgeClusterReconciler) failUpgrade(
+	ctx context.Context,
+	poller *bridgepoll.Poller,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster,
+	det bridgeapi.ClusterDetail,
+	phase, message string) (ctrl.Result, error) {
+
+	log.FromContext(ctx).Info("cluster upgrade "+strings.ToLower(phase),
+		"name", clusterObj.Spec.Name, "message", message)
+	clusterObj.Status.Phase = phase
+	clusterObj.Status.Message = message
+	clusterObj.Status.UpgradeStarted = ""
+	setStatusCondition(clusterObj, ConditionUpgradePending, metav1.ConditionFalse, ReasonUpgradeFailed, message)
+	setDegradedCondition(clusterObj, det)
+	if err := r.updateStatus(ctx, clusterObj); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonUpgradeFailed, message)
+	poller.Track(r.events, clusterObj, det.ID, det.Name, phase == crunchybridgev1alpha1.PhaseStalled)
+	return ctrl.Result{}, nil
+}
+
+// followFailedUpgrade watches the cluster of clusterObj after its upgrade
+// failed. Unlike failed provisioning the cluster is never deleted for a
+// retry, it holds the data. Once Bridge reports it upgraded after all, or
+// ready again after failing, clusterObj returns to the Ready phase, where a
+// failed upgrade is tried again only after the spec changes.
+func (r *BridgeClusterReconciler) followFailedUpgrade(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	poller *bridgepoll.Poller,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster) (ctrl.Result, error) {
+
+	detC, found, err := r.lookupCluster(ctx, bridgeClient, poller, clusterObj)
+	if errors.Is(err, bridgeapi.ErrorNotFound) {
+		return r.markLost(ctx, poller, clusterObj)
+	} else if err != nil {
+		return r.recordError(ctx, clusterObj, err)
+	} else if !found {
+		return ctrl.Result{}, nil
+	}
+	stalled := clusterObj.Status.Phase == crunchybridgev1alpha1.PhaseStalled
+	upgraded := detC.State == string(bridgeapi.StateReady) &&
+		detC.PGMajorVersion >= clusterObj.Spec.PGMajorVer
+	// A stalled upgrade may yet finish or fail outright, and may report
+	// ready before it has begun. A failed one that is ready again stays on
+	// the version it had.
+	if !upgraded && (stalled || detC.State != string(bridgeapi.StateReady)) {
+		if stalled && bridgeapi.ClusterState(detC.State).ProvisioningFailed() {
+			return r.failUpgrade(ctx, poller, clusterObj, detC, crunchybridgev1alpha1.PhaseFailed,
+				fmt.Sprintf("cluster %s reported state %q while upgrading to PostgreSQL %d",
+					detC.ID, detC.State, clusterObj.Spec.PGMajorVer))
+		}
+		poller.Track(r.events, clusterObj, detC.ID, detC.Name, stalled)
+		return ctrl.Result{}, nil
+	}
+
+	if _, err := r.updateStatusFromDetail(ctx, poller, detC, &clusterObj.Status); err != nil {
+		return r.recordError(ctx, clusterObj, err)
+	}
+	clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseReady
+	setDegradedCondition(clusterObj, detC)
+	if upgraded {
+		clusterObj.Status.Message = ""
+		apimeta.RemoveStatusCondition(&clusterObj.Status.Conditions, ConditionUpgradePending)
+	}
+	log.FromContext(ctx).Info("cluster ready after failed upgrade",
+		"name", clusterObj.Spec.Name, "version", detC.PGMajorVersion)
+	if err := r.updateStatus(ctx, clusterObj); err != nil {
+		return ctrl.Result{}, err
+	}
+	if upgraded {
+		r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonUpgraded,
+			"Cluster %s upgraded to PostgreSQL %d", detC.ID, detC.PGMajorVersion)
+	}
+	poller.Track(r.events, clusterObj, detC.ID, detC.Name, false)
+	return ctrl.Result{}, nil
+}
+
+// provisioningTimeout returns how long clusterObj may spend provisioning
+func provisioningTimeout(clusterObj *crunchybridgev1alpha1.BridgeCluster) time.Duration {
+	if t := clusterObj.Spec.ProvisioningTimeout; t != nil && t.Duration > 0 {
+		return t.Duration
+	}
+	return lifecycle.DefaultProvisioningTimeout
+}
+
+// pollerFor returns the poller watching clusters of the account, keyed
+// apart from those of DBaaS inventories in a shared registry
+func (r *BridgeClusterReconciler) pollerFor(accountRef string) *bridgepoll.Poller {
+	return r.Pollers.For("BridgeAccount/"+accountRef, func(ctx context.Context) (bridgeapi.Interface, func(), error) {
+		// Account clients are cached and closed by AccountClients
+		bc, err := r.Accounts.ClientFor(ctx, accountRef)
+		return bc, func() {}, err
+	})
+}
+
+// lookupCluster returns the cached detail of the cluster created for
+// clusterObj. Found is false while the poller has yet to list the clusters,
+// the object is sent back once it has. A cluster missing from the listing
+// is looked up directly, returning an error wrapping ErrorNotFound if it no
+// longer exists.
+func (r *BridgeClusterReconciler) lookupCluster(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	poller *bridgepoll.Poller,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster) (bridgeapi.ClusterDetail, bool, error) {
+
+	id := clusterObj.Status.Cluster.ID
+	detC, found, err := lifecycle.Lookup(ctx, bridgeClient, poller, id, clusterObj.Spec.Name)
+	if err == nil && !found {
+		poller.Track(r.events, clusterObj, id, clusterObj.Spec.Name, false)
+	}
+	return detC, found, err
+}
+
+// upgradeFromSpec starts an in-place major version upgrade when the spec of
+// clusterObj asks for a newer version than the cluster described by det
+// runs, moving it to the Upgrading phase. Under the Manual upgrade policy the
+// upgrade waits for approval through an annotation. Downgrades are refused,
+// both cases being explained in the status message and UpgradePending
+// condition until the spec or annotation changes. A failed upgrade is only
+// tried again once the spec has changed since.
+func (r *BridgeClusterReconciler) upgradeFromSpec(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster,
+	det bridgeapi.ClusterDetail) error {
+
+	target, current := clusterObj.Spec.PGMajorVer, det.PGMajorVersion
+	// An unset version, as when adopting without one, follows the cluster
+	if target == 0 || current == 0 || target == current {
+		if apimeta.FindStatusCondition(clusterObj.Status.Conditions, ConditionUpgradePending) != nil {
+			apimeta.RemoveStatusCondition(&clusterObj.Status.Conditions, ConditionUpgradePending)
+			clusterObj.Status.Message = ""
+		}
+		return nil
+	}
+
+	// A failed upgrade is left as reported until the spec changes again
+	if cond := apimeta.FindStatusCondition(clusterObj.Status.Conditions, ConditionUpgradePending); target > current &&
+		cond != nil && cond.Reason == ReasonUpgradeFailed && cond.ObservedGeneration == clusterObj.Generation {
+		return nil
+	}
+
+	var status metav1.ConditionStatus
+	var reason, eventType, msg string
+	switch {
+	case target < current:
+		status, reason, eventType = metav1.ConditionFalse, ReasonDowngradeRefused, corev1.EventTypeWarning
+		msg = fmt.Sprintf("pg_major_version %d is below the running version %d, downgrades are not supported",
+			target, current)
+	case clusterObj.Spec.UpgradePolicy == crunchybridgev1alpha1.UpgradePolicyManual &&
+		clusterObj.Annotations[crunchybridgev1alpha1.AnnotationApproveUpgrade] != strconv.Itoa(target):
+		status, reason, eventType = metav1.ConditionTrue, ReasonAwaitingApproval, corev1.EventTypeNormal
+		msg = fmt.Sprintf("upgrade from PostgreSQL %d to %d awaits the %s annotation set to %q",
+			current, target, crunchybridgev1alpha1.AnnotationApproveUpgrade, strconv.Itoa(target))
+	default:
+		log.FromContext(ctx).Info("cluster upgrade requested", "id", det.ID, "from", current, "to", target)
+		if err := bridgeClient.UpdateCluster(ctx, det.ID, bridgeapi.UpdateRequest{PGMajorVersion: target}); err != nil {
+			return err
+		}
+		clusterObj.Status.Phase = crunchybridgev1alpha1.PhaseUpgrading
+		clusterObj.Status.UpgradeStarted = time.Now().Format(time.RFC3339)
+		clusterObj.Status.Message = fmt.Sprintf("upgrading from PostgreSQL %d to %d", current, target)
+		setStatusCondition(clusterObj, ConditionUpgradePending, metav1.ConditionTrue, ReasonUpgrading,
+			clusterObj.Status.Message)
+		return nil
+	}
+
+	// Only report the wait once, the object is seen again on every resync
+	if clusterObj.Status.Message != msg {
+		r.Recorder.Event(clusterObj, eventType, reason, msg)
+	}
+	clusterObj.Status.Message = msg
+	setStatusCondition(clusterObj, ConditionUpgradePending, status, reason, msg)
+	return nil
+}
+
+// refuseStorageShrink reports through the UpdatePending condition a spec
+// asking for less storage than the cluster has. The webhook refuses such
+// changes, but the spec may predate it or the cluster may have been grown
+// outside the operator.
+func (r *BridgeClusterReconciler) refuseStorageShrink(
+	clusterObj *crunchybridgev1alpha1.BridgeCluster, det bridgeapi.ClusterDetail) {
+
+	if clusterObj.Spec.StorageGB >= det.StorageGB {
+		apimeta.RemoveStatusCondition(&clusterObj.Status.Conditions, ConditionUpdatePending)
+		return
+	}
+
+	msg := fmt.Sprintf("storage %dGB is below the %dGB of the cluster, storage can't be reduced",
+		clusterObj.Spec.StorageGB, det.StorageGB)
+	// Only report the refusal once, the object is seen again on every resync
+	if cond := apimeta.FindStatusCondition(clusterObj.Status.Conditions, ConditionUpdatePending); cond == nil || cond.Message != msg {
+		r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonShrinkRefused, msg)
+	}
+	setStatusCondition(clusterObj, ConditionUpdatePending, metav1.ConditionFalse, ReasonShrinkRefused, msg)
+}
+
+// reconcileReplicas brings the read replicas of the cluster described by det
+// in line with the spec of clusterObj and records them in its status.
+// Replicas are only created or removed while the cluster is ready. Only the
+// replicas whose IDs are recorded in the status, which the operator created,
+// are followed or removed; a replica of the same name made some other way is
+// reported as a conflict and left alone.
+// Pending reports whether any replica has yet to become ready or go away.
+func (r *BridgeClusterReconciler) reconcileReplicas(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster,
+	det bridgeapi.ClusterDetail) (bool, error) {
+
+	if len(clusterObj.Spec.Replicas) == 0 && len(clusterObj.Status.Replicas) == 0 {
+		apimeta.RemoveStatusCondition(&clusterObj.Status.Conditions, ConditionReplicasReady)
+		return false, nil
+	}
+	logger := log.FromContext(ctx)
+
+	list, err := bridgeClient.ListReplicas(ctx, det.ID)
+	if err != nil {
+		return false, err
+	}
+	existing := make(map[string]bridgeapi.ClusterDetail, len(list.Clusters))
+	named := make(map[string]bool, len(list.Clusters))
+	for _, rd := range list.Clusters {
+		existing[rd.ID] = rd
+		named[rd.Name] = true
+	}
+	recorded := make(map[string]string, len(clusterObj.Status.Replicas))
+	for _, prev := range clusterObj.Status.Replicas {
+		recorded[prev.Name] = prev.ID
+	}
+	wanted := make(map[string]bool, len(clusterObj.Spec.Replicas))
+	for _, rs := range clusterObj.Spec.Replicas {
+		wanted[rs.Name] = true
+	}
+	canChange := det.State == string(bridgeapi.StateReady)
+
+	var replicas []crunchybridgev1alpha1.ReplicaStatus
+	pending := false
+	for _, prev := range clusterObj.Status.Replicas {
+		rd, ok := existing[prev.ID]
+		if wanted[prev.Name] || !ok {
+			continue
+		}
+		// Keep following replicas dropped from the spec until they're gone
+		pending = true
+		if canChange && rd.State != string(bridgeapi.StateDestroying) {
+			logger.Info("deleting replica", "id", rd.ID, "name", rd.Name)
+			if err := bridgeClient.DeleteReplica(ctx, det.ID, rd.ID); err != nil {
+				return false, err
+			}
+			r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonReplicaDeleted,
+				"Deleted replica %s (%s)", rd.Name, rd.ID)
+			rd.State = string(bridgeapi.StateDestroying)
+		}
+		replicas = append(replicas, replicaStatus(prev.Name, rd))
+	}
+
+	ready := 0
+	var conflicts []string
+	for _, rs := range clusterObj.Spec.Replicas {
+		rd, ok := existing[recorded[rs.Name]]
+		if !ok && named[rs.Name] {
+			conflicts = append(conflicts, rs.Name)
+			continue
+		}
+		if !ok {
+			if !canChange {
+				pending = true
+				continue
+			}
+			logger.Info("replica create requested", "name", rs.Name, "region", rs.Region)
+			rd, err = bridgeClient.CreateReplica(ctx, det.ID, bridgeapi.ReplicaRequest{
+				Name:     rs.Name,
+				Plan:     rs.Plan,
+				Provider: rs.Provider,
+				Region:   rs.Region,
+			})
+			if err != nil {
+				return false, err
+			}
+			// Record the ID straight away, a replica missing from the status
+			// would be taken for someone else's on the next pass
+			clusterObj.Status.Replicas = append(clusterObj.Status.Replicas, replicaStatus(rs.Name, rd))
+			if err := r.updateStatus(ctx, clusterObj); err != nil {
+				if delErr := bridgeClient.DeleteReplica(ctx, det.ID, rd.ID); delErr != nil {
+					logger.Error(delErr, "unable to delete unrecorded replica", "id", rd.ID, "name", rs.Name)
+				}
+				return false, err
+			}
+			r.Recorder.Eventf(clusterObj, corev1.EventTypeNormal, ReasonReplicaCreateRequested,
+				"Requested creation of replica %s", rs.Name)
+		}
+		if rd.State == string(bridgeapi.StateReady) {
+			ready++
+		} else {
+			pending = true
+		}
+		replicas = append(replicas, replicaStatus(rs.Name, rd))
+	}
+	clusterObj.Status.Replicas = replicas
+
+	switch {
+	case len(clusterObj.Spec.Replicas) == 0:
+		apimeta.RemoveStatusCondition(&clusterObj.Status.Conditions, ConditionReplicasReady)
+	case len(conflicts) > 0:
+		msg := fmt.Sprintf("replicas %s exist but were not created by the operator",
+			strings.Join(conflicts, ", "))
+		// Only report the conflict once, the object is seen again on every resync
+		if cond := apimeta.FindStatusCondition(clusterObj.Status.Conditions, ConditionReplicasReady); cond == nil || cond.Message != msg {
+			r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonReplicaConflict, msg)
+		}
+		setStatusCondition(clusterObj, ConditionReplicasReady, metav1.ConditionFalse, ReasonReplicaConflict, msg)
+	case ready == len(clusterObj.Spec.Replicas):
+		setStatusCondition(clusterObj, ConditionReplicasReady, metav1.ConditionTrue, ReasonAvailable, "")
+	default:
+		setStatusCondition(clusterObj, ConditionReplicasReady, metav1.ConditionFalse, ReasonReplicaState,
+			fmt.Sprintf("%d of %d replicas ready", ready, len(clusterObj.Spec.Replicas)))
+	}
+	return pending, nil
+}
+
+// replicaStatus describes the replica named in the spec by name
+func replicaStatus(name string, det bridgeapi.ClusterDetail) crunchybridgev1alpha1.ReplicaStatus {
+	return crunchybridgev1alpha1.ReplicaStatus{
+		Name:       name,
+		ID:         det.ID,
+		State:      det.State,
+		Plan:       det.PlanID,
+		ProviderID: det.ProviderID,
+		RegionID:   det.RegionID,
+		Host:       det.Host,
+	}
+}
+
+// setDegradedCondition flags a cluster that Bridge reports as anything other
+// than ready once provisioning has completed
+func setDegradedCondition(clusterObj *crunchybridgev1alpha1.BridgeCluster, det bridgeapi.ClusterDetail) {
+	if det.State != string(bridgeapi.StateReady) {
+		setStatusCondition(clusterObj, ConditionDegraded, metav1.ConditionTrue, ReasonClusterState,
+			fmt.Sprintf("cluster reported state %q", det.State))
+		return
+	}
+	setStatusCondition(clusterObj, ConditionDegraded, metav1.ConditionFalse, ReasonAvailable, "")
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *BridgeClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("bridgecluster-controller")
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+	if r.Pollers == nil {
+		r.Pollers = &bridgepoll.Registry{}
+		if err := mgr.Add(r.Pollers); err != nil {
+			return err
+		}
+	}
+	r.events = make(chan event.GenericEvent)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&crunchybridgev1alpha1.BridgeCluster{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Channel{Source: r.events}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
 
@@ -186,7 +1125,11 @@ func listContains(list []string, s string) bool {
 	return false
 }
 
-func (r *BridgeClusterReconciler) createFromSpec(spec crunchybridgev1alpha1.BridgeClusterSpec) (bridgeapi.CreateRequest, error) {
+func (r *BridgeClusterReconciler) createFromSpec(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	spec crunchybridgev1alpha1.BridgeClusterSpec) (bridgeapi.CreateRequest, error) {
+
 	req := bridgeapi.CreateRequest{
 		Name:             spec.Name,
 		TeamID:           spec.TeamID,
@@ -197,10 +1140,14 @@ func (r *BridgeClusterReconciler) createFromSpec(spec crunchybridgev1alpha1.Brid
 		PGMajorVersion:   spec.PGMajorVer,
 		HighAvailability: spec.HighAvail,
 	}
+	if req.PGMajorVersion == 0 {
+		// Not defaulted when the webhooks are disabled
+		req.PGMajorVersion = crunchybridgev1alpha1.DefaultPGMajorVersion
+	}
 
 	if tid := spec.TeamID; tid == "" {
 		// Lookup TeamID
-		if id, err := r.BridgeClient.DefaultTeamID(); err != nil {
+		if id, err := bridgeClient.DefaultTeamID(ctx); err != nil {
 			return req, err
 		} else {
 			req.TeamID = id
@@ -210,17 +1157,78 @@ func (r *BridgeClusterReconciler) createFromSpec(spec crunchybridgev1alpha1.Brid
 	return req, nil
 }
 
+// verifyAdoptable checks that an existing cluster agrees with the spec on
+// every attribute which can't be changed after creation. Differences in plan,
+// storage and HA are left to be resolved as a regular update
+func verifyAdoptable(spec crunchybridgev1alpha1.BridgeClusterSpec, det bridgeapi.ClusterDetail) error {
+	if det.ID == "" {
+		return errors.New("received cluster detail with no ID")
+	}
+
+	var mismatch []string
+	if spec.Name != det.Name {
+		mismatch = append(mismatch, fmt.Sprintf("name (%q != %q)", spec.Name, det.Name))
+	}
+	if spec.TeamID != "" && spec.TeamID != det.TeamID {
+		mismatch = append(mismatch, fmt.Sprintf("team_id (%q != %q)", spec.TeamID, det.TeamID))
+	}
+	if spec.Provider != det.ProviderID {
+		mismatch = append(mismatch, fmt.Sprintf("provider (%q != %q)", spec.Provider, det.ProviderID))
+	}
+	if spec.Region != det.RegionID {
+		mismatch = append(mismatch, fmt.Sprintf("region (%q != %q)", spec.Region, det.RegionID))
+	}
+	if spec.PGMajorVer != 0 && spec.PGMajorVer != det.PGMajorVersion {
+		mismatch = append(mismatch, fmt.Sprintf("pg_major_version (%d != %d)", spec.PGMajorVer, det.PGMajorVersion))
+	}
+
+	if len(mismatch) > 0 {
+		return fmt.Errorf("spec does not match cluster %s: %s", det.ID, strings.Join(mismatch, ", "))
+	}
+	return nil
+}
+
+// updateFromSpec compares the mutable attributes of the spec with the live
+// cluster, returning the request needed to bring the cluster in line with
+// the spec and whether any change is needed at all
+func updateFromSpec(spec crunchybridgev1alpha1.BridgeClusterSpec, det bridgeapi.ClusterDetail) (bridgeapi.UpdateRequest, bool) {
+	req := bridgeapi.UpdateRequest{}
+	changed := false
+
+	// An unreported plan can't be compared, leave it be rather than
+	// re-requesting the same plan on every pass
+	if det.PlanID != "" && spec.Plan != det.PlanID {
+		req.Plan = spec.Plan
+		changed = true
+	}
+	// Storage can only grow, refuseStorageShrink reports smaller requests
+	if spec.StorageGB > det.StorageGB {
+		req.StorageGB = spec.StorageGB
+		changed = true
+	}
+	if spec.HighAvail != det.HighAvailability {
+		ha := spec.HighAvail
+		req.HighAvailability = &ha
+		changed = true
+	}
+
+	return req, changed
+}
+
 // updateStatusFromDetail performs an update to the status of the API object
-// if an error case is returned, it is assumed the status will not be
+// and returns the default connection role used to fill in the connection
+// detail. If an error case is returned, it is assumed the status will not be
 // written back to the server, so in-place changes will be lost
 func (r *BridgeClusterReconciler) updateStatusFromDetail(
+	ctx context.Context,
+	poller *bridgepoll.Poller,
 	det bridgeapi.ClusterDetail,
-	statusObj *crunchybridgev1alpha1.BridgeClusterStatus) error {
+	statusObj *crunchybridgev1alpha1.BridgeClusterStatus) (bridgeapi.ConnectionRole, error) {
 
 	// Using ID field as a heuristic that a valid ClusterDetail was provided
 	// and blindly updating the detail from there instead of per-field checks
 	if det.ID == "" {
-		return errors.New("received cluster detail with no ID")
+		return bridgeapi.ConnectionRole{}, errors.New("received cluster detail with no ID")
 	}
 
 	// Who
@@ -228,6 +1236,7 @@ func (r *BridgeClusterReconciler) updateStatusFromDetail(
 	statusObj.Cluster.Name = det.Name
 	statusObj.Cluster.TeamID = det.TeamID
 	// What
+	statusObj.Cluster.Plan = det.PlanID
 	statusObj.Cluster.CPU = det.CPU
 	statusObj.Cluster.MemoryGB = det.MemoryGB
 	statusObj.Cluster.StorageGB = det.StorageGB
@@ -240,19 +1249,55 @@ func (r *BridgeClusterReconciler) updateStatusFromDetail(
 	statusObj.Cluster.ProviderID = det.ProviderID
 	statusObj.Cluster.RegionID = det.RegionID
 
-	if role, err := r.BridgeClient.DefaultConnRole(det.ID); err != nil {
-		return fmt.Errorf("Unable to get connection role: %w\n", err)
-	} else {
-		dbURL, err := url.Parse(role.URI)
-		if err != nil {
-			return err
-		}
-		dbURL.User = nil
-		statusObj.Connect.URI = dbURL.String()
-		statusObj.Connect.ParentDBRole = role.Name
-		statusObj.Connect.DatabaseName = strings.TrimLeft(dbURL.Path, "/")
+	role, err := poller.DefaultConnRole(ctx, det.ID)
+	if err != nil {
+		return role, fmt.Errorf("Unable to get connection role: %w\n", err)
 	}
+	dbURL, err := url.Parse(role.URI)
+	if err != nil {
+		return role, err
+	}
+	dbURL.User = nil
+	statusObj.Connect.URI = dbURL.String()
+	statusObj.Connect.ParentDBRole = role.Name
+	statusObj.Connect.DatabaseName = strings.TrimLeft(dbURL.Path, "/")
 
 	statusObj.Updated = time.Now().Format(time.RFC3339)
-	return nil
+	return role, nil
+}
+
+// writeConnectionSecret creates or updates the secret requested through
+// spec.write_connection_secret_to_ref with the connection detail of role,
+// leaving things alone when no secret is requested
+func (r *BridgeClusterReconciler) writeConnectionSecret(
+	ctx context.Context,
+	clusterObj *crunchybridgev1alpha1.BridgeCluster,
+	role bridgeapi.ConnectionRole) error {
+
+	ref := clusterObj.Spec.ConnectionSecretRef
+	if ref == nil || ref.Name == "" {
+		return nil
+	}
+
+	dbURL, err := url.Parse(role.URI)
+	if err != nil {
+		return err
+	}
+	secret, err := newOwnedSecret(clusterObj, r.Scheme, ref.Name, "BridgeCluster",
+		map[string][]byte{
+			"host":     []byte(dbURL.Hostname()),
+			"port":     []byte(dbURL.Port()),
+			"database": []byte(strings.TrimLeft(dbURL.Path, "/")),
+			"user":     []byte(role.Name),
+			"password": []byte(role.Password),
+			"uri":      []byte(role.URI),
+		})
+	if err != nil {
+		return err
+	}
+	err = applySecret(ctx, r.Client, r.APIReader, clusterObj, secret)
+	if errors.Is(err, errSecretConflict) {
+		r.Recorder.Event(clusterObj, corev1.EventTypeWarning, ReasonSecretConflict, err.Error())
+	}
+	return err
 }
@@ -0,0 +1,543 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crunchybridge
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/reconciletest"
+)
+
+// newClusterReconciler returns a BridgeClusterReconciler using the account
+// of env by default
+func newClusterReconciler(env *reconciletest.Env) *BridgeClusterReconciler {
+	return &BridgeClusterReconciler{
+		Client:    env.Client,
+		Scheme:    env.Scheme,
+		Recorder:  env.Recorder,
+		Accounts:  &AccountClients{Reader: env.Client, Default: env.Bridge, APIURL: env.Server.APIURL()},
+		APIReader: env.Client,
+		Pollers:   env.Pollers,
+		events:    env.Events,
+	}
+}
+
+// newCluster returns a BridgeCluster asking for a cluster named name
+func newCluster(name string) *crunchybridgev1alpha1.BridgeCluster {
+	return &crunchybridgev1alpha1.BridgeCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: name},
+		Spec: crunchybridgev1alpha1.BridgeClusterSpec{
+			Name:       name,
+			Plan:       "hobby-2",
+			StorageGB:  10,
+			Provider:   "aws",
+			Region:     "us-east-1",
+			PGMajorVer: 13,
+		},
+	}
+}
+
+// inPhase returns a condition for ReconcileUntil waiting on obj to reach
+// phase
+func inPhase(obj *crunchybridgev1alpha1.BridgeCluster, phase string) func(bool) bool {
+	return func(found bool) bool { return found && obj.Status.Phase == phase }
+}
+
+// ready creates obj and reconciles it until its cluster is provisioned and
+// ready
+func ready(env *reconciletest.Env, r *BridgeClusterReconciler, obj *crunchybridgev1alpha1.BridgeCluster) {
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseCreating))
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+}
+
+func condition(obj ObjectWithStatusConditions, condType string) metav1.Condition {
+	if cond := apimeta.FindStatusCondition(*obj.GetStatusConditions(), condType); cond != nil {
+		return *cond
+	}
+	return metav1.Condition{}
+}
+
+func TestBridgeClusterLifecycle(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("lifecycle")
+	ready(env, r, obj)
+
+	dets := env.ClustersNamed("lifecycle")
+	if len(dets) != 1 || obj.Status.Cluster.ID != dets[0].ID || dets[0].TeamID != env.TeamID {
+		t.Fatalf("cluster %q of BridgeCluster, Bridge has %+v", obj.Status.Cluster.ID, dets)
+	}
+
+	env.Modify(obj, func() { obj.Spec.StorageGB = 20 })
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return obj.Status.Phase == crunchybridgev1alpha1.PhaseReady && obj.Status.Cluster.StorageGB == 20
+	})
+	if !env.Recorded(ReasonUpdated) {
+		t.Errorf("no %s event for the storage change", ReasonUpdated)
+	}
+
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if dets := env.ClustersNamed("lifecycle"); len(dets) != 0 {
+		t.Errorf("clusters after deletion = %+v", dets)
+	}
+}
+
+func TestBridgeClusterSettled(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("settled")
+	ready(env, r, obj)
+
+	// The first pass once ready fills in the Degraded condition, those over
+	// an unchanged cluster after it write nothing, which would otherwise
+	// come back as watch events for further passes
+	if err := env.ReconcileTimes(r, obj, 1); err != nil {
+		t.Fatal(err)
+	}
+	version := obj.ResourceVersion
+	if err := env.ReconcileTimes(r, obj, 3); err != nil {
+		t.Fatal(err)
+	}
+	if obj.ResourceVersion != version {
+		t.Errorf("resource version moved from %s to %s without changes", version, obj.ResourceVersion)
+	}
+}
+
+func TestBridgeClusterStorageShrink(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("shrink")
+	ready(env, r, obj)
+
+	env.Modify(obj, func() { obj.Spec.StorageGB = 5 })
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionUpdatePending).Reason == ReasonShrinkRefused
+	})
+	if obj.Status.Phase != crunchybridgev1alpha1.PhaseReady || !env.Recorded(ReasonShrinkRefused) {
+		t.Errorf("shrink left phase %q", obj.Status.Phase)
+	}
+	if det, _ := env.Server.Cluster(obj.Status.Cluster.ID); det.StorageGB != 10 {
+		t.Errorf("storage after refused shrink = %d; want 10", det.StorageGB)
+	}
+}
+
+func TestBridgeClusterAdoption(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	id := env.AddReadyCluster("existing")
+
+	obj := newCluster("existing")
+	obj.Spec.ClusterID = id
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+	if obj.Status.Cluster.ID != id || !env.Recorded(ReasonAdopted) {
+		t.Errorf("adopted cluster %q; want %q with an %s event", obj.Status.Cluster.ID, id, ReasonAdopted)
+	}
+	if dets := env.ClustersNamed("existing"); len(dets) != 1 {
+		t.Errorf("clusters after adoption = %+v; want only the existing one", dets)
+	}
+
+	// Clusters not matching the spec are refused
+	other := newCluster("existing-elsewhere")
+	other.Spec.ClusterID = id
+	env.Create(other)
+	if err := env.ReconcileTimes(r, other, 2); err == nil {
+		t.Error("adopting a cluster of another name succeeded")
+	}
+	if cond := condition(other, ConditionReady); other.Status.Phase != crunchybridgev1alpha1.PhasePending || cond.Reason != ReasonSpecMismatch {
+		t.Errorf("mismatched adoption in phase %q with Ready %+v", other.Status.Phase, cond)
+	}
+}
+
+func TestBridgeClusterDeletionPolicy(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("retained")
+	obj.Spec.DeletionPolicy = crunchybridgev1alpha1.DeletionPolicyRetain
+	obj.Annotations = map[string]string{crunchybridgev1alpha1.AnnotationDeletionProtection: "true"}
+	ready(env, r, obj)
+	id := obj.Status.Cluster.ID
+
+	// Protection holds the object and its cluster until the annotation goes
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionReady).Reason == ReasonDeletionProtected
+	})
+	if err := env.ReconcileTimes(r, obj, 2); err != nil || len(obj.Finalizers) == 0 || !env.Recorded(ReasonDeletionProtected) {
+		t.Fatalf("protected deletion: %v, finalizers %v", err, obj.Finalizers)
+	}
+
+	env.Modify(obj, func() { delete(obj.Annotations, crunchybridgev1alpha1.AnnotationDeletionProtection) })
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if det, ok := env.Server.Cluster(id); !ok || det.State != string(bridgeapi.StateReady) || !env.Recorded(ReasonRetained) {
+		t.Errorf("retained cluster %+v, found %v", det, ok)
+	}
+}
+
+func TestBridgeClusterConnectionSecret(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("connected")
+	obj.Spec.ConnectionSecretRef = &corev1.LocalObjectReference{Name: "connected-conn"}
+	ready(env, r, obj)
+
+	// The secret follows on the first pass once ready
+	if err := env.ReconcileTimes(r, obj, 1); err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: "connected-conn"}}
+	if !env.Get(secret) {
+		t.Fatal("connection secret not written")
+	}
+	if string(secret.Data["user"]) != "postgres" || len(secret.Data["password"]) == 0 || !metav1.IsControlledBy(secret, obj) {
+		t.Errorf("connection secret = %q", secret.Data)
+	}
+
+	// Secrets of the same name the operator doesn't control are left alone
+	unowned := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: "unowned-conn"},
+		Data:       map[string][]byte{"password": []byte("unrelated")},
+	}
+	env.Create(unowned)
+	env.Modify(obj, func() { obj.Spec.ConnectionSecretRef.Name = "unowned-conn" })
+	if err := env.ReconcileTimes(r, obj, 2); err == nil || !env.Recorded(ReasonSecretConflict) {
+		t.Errorf("writing an unowned secret: %v", err)
+	}
+	env.Get(unowned)
+	if string(unowned.Data["password"]) != "unrelated" {
+		t.Errorf("unowned secret overwritten: %q", unowned.Data)
+	}
+}
+
+func TestBridgeClusterProvisioningTimeout(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("slow")
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseCreating))
+	id := obj.Status.Cluster.ID
+
+	env.Modify(obj, func() {
+		obj.Status.ProvisioningStarted = time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	})
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseStalled))
+	if !env.Recorded(ReasonStalled) {
+		t.Errorf("no %s event", ReasonStalled)
+	}
+
+	// A stalled cluster is still followed and taken up once ready
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+	if obj.Status.Cluster.ID != id {
+		t.Errorf("ready with cluster %q; want %q", obj.Status.Cluster.ID, id)
+	}
+}
+
+func TestBridgeClusterProvisioningRetry(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	// Deleted clusters stay listed, destroying, under their name for a while
+	env.Server.SetDeletionTime(time.Hour)
+
+	obj := newCluster("retried")
+	obj.Spec.ProvisioningFailurePolicy = crunchybridgev1alpha1.ProvisioningFailurePolicyRetry
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseCreating))
+	failedID := obj.Status.Cluster.ID
+	if failedID == "" {
+		t.Fatal("created cluster ID not recorded")
+	}
+
+	env.Server.SetClusterState(failedID, bridgeapi.StateFailed)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseFailed))
+
+	// Skip the backoff
+	env.Modify(obj, func() {
+		obj.Status.Updated = time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	})
+	env.ReconcileUntil(r, obj, func(bool) bool { return obj.Status.DeletionStarted != "" })
+
+	// While the old cluster is destroying, nothing is created in its place
+	if err := env.ReconcileTimes(r, obj, 10); err != nil {
+		t.Errorf("reconciling while the old cluster is destroyed: %v", err)
+	}
+	dets := env.ClustersNamed("retried")
+	if obj.Status.Phase != crunchybridgev1alpha1.PhaseFailed || obj.Status.ProvisioningAttempts != 1 ||
+		len(dets) != 1 || dets[0].State != string(bridgeapi.StateDestroying) {
+		t.Fatalf("while destroying: phase %q after %d attempts, Bridge has %+v",
+			obj.Status.Phase, obj.Status.ProvisioningAttempts, dets)
+	}
+
+	env.Server.Advance(time.Hour)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseCreating))
+	if obj.Status.Cluster.ID == "" || obj.Status.Cluster.ID == failedID || obj.Status.ProvisioningAttempts != 2 {
+		t.Fatalf("retry created cluster %q after %d attempts; want a new one", obj.Status.Cluster.ID, obj.Status.ProvisioningAttempts)
+	}
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+}
+
+func TestBridgeClusterRejectedCreate(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("rejected")
+	obj.Spec.ProvisioningFailurePolicy = crunchybridgev1alpha1.ProvisioningFailurePolicyRetry
+	env.Create(obj)
+	env.Server.InjectFault(bridgetest.Fault{Method: "POST", Path: "/clusters", Status: 400})
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseFailed))
+	if obj.Status.Cluster.ID != "" || !strings.Contains(obj.Status.Message, "rejected") {
+		t.Fatalf("rejected create left cluster %q: %s", obj.Status.Cluster.ID, obj.Status.Message)
+	}
+
+	// Bridge would reject the same request again, it isn't retried
+	env.Modify(obj, func() {
+		obj.Status.Updated = time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	})
+	env.ReconcileTimes(r, obj, 3)
+	if obj.Status.Phase != crunchybridgev1alpha1.PhaseFailed || obj.Status.ProvisioningAttempts != 1 {
+		t.Errorf("rejected create in phase %q after %d attempts; want Failed after 1", obj.Status.Phase, obj.Status.ProvisioningAttempts)
+	}
+}
+
+func TestBridgeClusterDeletionTimeout(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	env.Server.SetDeletionTime(time.Hour)
+	obj := newCluster("stuck")
+	ready(env, r, obj)
+	id := obj.Status.Cluster.ID
+
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return strings.HasPrefix(obj.Status.Message, "waiting for removal")
+	})
+	if obj.Status.Phase != crunchybridgev1alpha1.PhaseDeleting || len(obj.Finalizers) == 0 {
+		t.Fatalf("while destroying: phase %q with finalizers %v", obj.Status.Phase, obj.Finalizers)
+	}
+
+	env.Modify(obj, func() {
+		obj.Status.DeletionStarted = time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	})
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return strings.Contains(obj.Status.Message, crunchybridgev1alpha1.AnnotationForceRemove)
+	})
+	if !env.Recorded(ReasonDeletionStalled) {
+		t.Errorf("no %s event", ReasonDeletionStalled)
+	}
+
+	env.Modify(obj, func() {
+		obj.Annotations = map[string]string{crunchybridgev1alpha1.AnnotationForceRemove: "true"}
+	})
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if _, ok := env.Server.Cluster(id); !ok || !env.Recorded(ReasonForceRemoved) {
+		t.Errorf("force removal: cluster kept %v, %s event %v", ok, ReasonForceRemoved, env.Recorded(ReasonForceRemoved))
+	}
+}
+
+func TestBridgeClusterLost(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("lost")
+	obj.Spec.RecoveryPolicy = crunchybridgev1alpha1.RecoveryPolicyRecreate
+	ready(env, r, obj)
+	lostID := obj.Status.Cluster.ID
+
+	if err := env.Bridge.DeleteCluster(env.Ctx, lostID); err != nil {
+		t.Fatal(err)
+	}
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseLost))
+	if !env.Recorded(ReasonLost) {
+		t.Errorf("no %s event", ReasonLost)
+	}
+
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseCreating))
+	if obj.Status.Cluster.ID == "" || obj.Status.Cluster.ID == lostID {
+		t.Fatalf("recreated cluster %q; want a new one", obj.Status.Cluster.ID)
+	}
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+}
+
+func TestBridgeClusterReplicas(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("primary")
+	ready(env, r, obj)
+
+	env.Modify(obj, func() {
+		obj.Spec.Replicas = []crunchybridgev1alpha1.ReplicaSpec{{Name: "primary-west", Region: "us-west-2"}}
+	})
+	env.ReconcileUntil(r, obj, func(bool) bool { return len(obj.Status.Replicas) == 1 })
+	if cond := condition(obj, ConditionReplicasReady); cond.Status != metav1.ConditionFalse {
+		t.Errorf("ReplicasReady while provisioning = %+v", cond)
+	}
+
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionReplicasReady).Status == metav1.ConditionTrue
+	})
+	if rs := obj.Status.Replicas[0]; rs.RegionID != "us-west-2" || rs.State != string(bridgeapi.StateReady) {
+		t.Errorf("replica status = %+v", rs)
+	}
+
+	replicaID := obj.Status.Replicas[0].ID
+	env.Modify(obj, func() { obj.Spec.Replicas = nil })
+	env.ReconcileUntil(r, obj, func(bool) bool { return len(obj.Status.Replicas) == 0 })
+	if _, ok := env.Server.Cluster(replicaID); ok {
+		t.Errorf("replica %s kept after removal from the spec", replicaID)
+	}
+}
+
+func TestBridgeClusterReplicaConflict(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("shared")
+	ready(env, r, obj)
+
+	// A replica made outside the operator is neither adopted nor removed
+	other, err := env.Bridge.CreateReplica(env.Ctx, obj.Status.Cluster.ID,
+		bridgeapi.ReplicaRequest{Name: "shared-west", Region: "us-west-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Modify(obj, func() {
+		obj.Spec.Replicas = []crunchybridgev1alpha1.ReplicaSpec{{Name: "shared-west", Region: "us-west-2"}}
+	})
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionReplicasReady).Reason == ReasonReplicaConflict
+	})
+	if len(obj.Status.Replicas) != 0 || !env.Recorded(ReasonReplicaConflict) {
+		t.Errorf("conflicting replica recorded as %+v", obj.Status.Replicas)
+	}
+
+	env.Modify(obj, func() { obj.Spec.Replicas = nil })
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return apimeta.FindStatusCondition(obj.Status.Conditions, ConditionReplicasReady) == nil
+	})
+	if _, ok := env.Server.Cluster(other.ID); !ok || env.Recorded(ReasonReplicaDeleted) {
+		t.Errorf("replica %s made outside the operator was deleted", other.ID)
+	}
+}
+
+func TestBridgeClusterUpgrade(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("upgraded")
+	obj.Spec.UpgradePolicy = crunchybridgev1alpha1.UpgradePolicyManual
+	ready(env, r, obj)
+
+	env.Modify(obj, func() { obj.Spec.PGMajorVer = 14 })
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionUpgradePending).Reason == ReasonAwaitingApproval
+	})
+	if obj.Status.Phase != crunchybridgev1alpha1.PhaseReady {
+		t.Errorf("unapproved upgrade moved to phase %q", obj.Status.Phase)
+	}
+
+	env.Modify(obj, func() {
+		obj.Annotations = map[string]string{crunchybridgev1alpha1.AnnotationApproveUpgrade: "14"}
+	})
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseUpgrading))
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+	if obj.Status.Cluster.PGMajorVer != 14 || !env.Recorded(ReasonUpgraded) {
+		t.Errorf("upgraded to %d", obj.Status.Cluster.PGMajorVer)
+	}
+
+	// Downgrades are refused
+	env.Modify(obj, func() { obj.Spec.PGMajorVer = 13 })
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionUpgradePending).Reason == ReasonDowngradeRefused
+	})
+}
+
+func TestBridgeClusterUpgradeFailed(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("upgrade-failed")
+	ready(env, r, obj)
+	id := obj.Status.Cluster.ID
+
+	// The fake client leaves generations alone, bump them as the API server would
+	env.Modify(obj, func() { obj.Spec.PGMajorVer, obj.Generation = 14, obj.Generation+1 })
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseUpgrading))
+	env.Server.SetClusterState(id, bridgeapi.StateFailed)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseFailed))
+	if cond := condition(obj, ConditionUpgradePending); cond.Reason != ReasonUpgradeFailed || !env.Recorded(ReasonUpgradeFailed) {
+		t.Errorf("UpgradePending after failure = %+v", cond)
+	}
+
+	// The cluster holds the data, it is kept rather than replaced
+	if err := env.ReconcileTimes(r, obj, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := env.Server.Cluster(id); !ok || obj.Status.Phase != crunchybridgev1alpha1.PhaseFailed {
+		t.Fatalf("failed upgrade in phase %q, cluster kept %v", obj.Status.Phase, ok)
+	}
+
+	// Once Bridge recovers the cluster, the upgrade waits for a spec change
+	env.Server.SetClusterState(id, bridgeapi.StateReady)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+	if err := env.ReconcileTimes(r, obj, 3); err != nil {
+		t.Fatal(err)
+	}
+	if det, _ := env.Server.Cluster(id); det.State != string(bridgeapi.StateReady) ||
+		obj.Status.Phase != crunchybridgev1alpha1.PhaseReady ||
+		condition(obj, ConditionUpgradePending).Reason != ReasonUpgradeFailed {
+		t.Fatalf("after recovery: phase %q, Bridge state %q", obj.Status.Phase, det.State)
+	}
+
+	env.Modify(obj, func() { obj.Generation++ })
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseUpgrading))
+}
+
+func TestBridgeClusterUpgradeTimeout(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newClusterReconciler(env)
+	obj := newCluster("upgrade-slow")
+	ready(env, r, obj)
+
+	env.Modify(obj, func() { obj.Spec.PGMajorVer = 14 })
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseUpgrading))
+	env.Modify(obj, func() {
+		obj.Status.UpgradeStarted = time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	})
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseStalled))
+	if cond := condition(obj, ConditionUpgradePending); cond.Reason != ReasonUpgradeFailed {
+		t.Errorf("UpgradePending after timeout = %+v", cond)
+	}
+
+	// A stalled upgrade is still followed and taken up once done
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inPhase(obj, crunchybridgev1alpha1.PhaseReady))
+	if obj.Status.Cluster.PGMajorVer != 14 || !env.Recorded(ReasonUpgraded) ||
+		apimeta.FindStatusCondition(obj.Status.Conditions, ConditionUpgradePending) != nil {
+		t.Errorf("stalled upgrade finished at version %d with conditions %+v",
+			obj.Status.Cluster.PGMajorVer, obj.Status.Conditions)
+	}
+}
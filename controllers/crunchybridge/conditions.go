@@ -0,0 +1,182 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crunchybridge
+
+import (
+	"errors"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+)
+
+// Condition types
+const (
+	ConditionReady               string = "Ready"
+	ConditionProvisioning        string = "Provisioning"
+	ConditionDegraded            string = "Degraded"
+	ConditionReplicasReady       string = "ReplicasReady"
+	ConditionUpgradePending      string = "UpgradePending"
+	ConditionUpdatePending       string = "UpdatePending"
+	ConditionBackendError        string = "BackendError"
+	ConditionAuthenticationError string = "AuthenticationError"
+)
+
+// Condition reasons
+const (
+	ReasonPending            string = "Pending"
+	ReasonCreating           string = "Creating"
+	ReasonUpdating           string = "Updating"
+	ReasonUpgrading          string = "Upgrading"
+	ReasonDeleting           string = "Deleting"
+	ReasonFailed             string = "Failed"
+	ReasonStalled            string = "Stalled"
+	ReasonLost               string = "Lost"
+	ReasonAvailable          string = "Available"
+	ReasonClusterState       string = "ClusterState"
+	ReasonReplicaState       string = "ReplicaState"
+	ReasonReplicaConflict    string = "ReplicaConflict"
+	ReasonAwaitingApproval   string = "AwaitingApproval"
+	ReasonDowngradeRefused   string = "DowngradeRefused"
+	ReasonUpgradeFailed      string = "UpgradeFailed"
+	ReasonShrinkRefused      string = "ShrinkRefused"
+	ReasonDeletionProtected  string = "DeletionProtected"
+	ReasonDeletionStalled    string = "DeletionStalled"
+	ReasonSpecMismatch       string = "SpecMismatch"
+	ReasonConflict           string = "Conflict"
+	ReasonAPIError           string = "APIError"
+	ReasonAPIReachable       string = "APIReachable"
+	ReasonInvalidCredentials string = "InvalidCredentials"
+	ReasonMissingCredentials string = "MissingCredentials"
+	ReasonLoginPending       string = "LoginPending"
+	ReasonAuthenticated      string = "Authenticated"
+)
+
+// Event reasons for lifecycle steps, in addition to the condition reasons
+const (
+	ReasonAdopted         string = "Adopted"
+	ReasonCreateRequested string = "CreateRequested"
+	ReasonCreated         string = "Created"
+	ReasonUpdateRequested string = "UpdateRequested"
+	ReasonUpdated         string = "Updated"
+	ReasonUpgraded        string = "Upgraded"
+	ReasonDeleted         string = "Deleted"
+	ReasonRetained        string = "Retained"
+	ReasonRestored        string = "Restored"
+	ReasonRetrying        string = "Retrying"
+	ReasonForceRemoved    string = "ForceRemoved"
+	ReasonRecreating      string = "Recreating"
+	ReasonSecretConflict  string = "SecretConflict"
+
+	ReasonReplicaCreateRequested string = "ReplicaCreateRequested"
+	ReasonReplicaDeleted         string = "ReplicaDeleted"
+	ReasonUpgradeRequested       string = "UpgradeRequested"
+)
+
+// ObjectWithStatusConditions is an interface that describes kubernetes resource
+// type structs with Status Conditions
+type ObjectWithStatusConditions interface {
+	client.Object
+	GetStatusConditions() *[]metav1.Condition
+}
+
+// setStatusCondition sets the given condition with the given status,
+// reason and message on a resource, tagged with the resource generation
+func setStatusCondition(obj ObjectWithStatusConditions, condition string, status metav1.ConditionStatus, reason, message string) {
+	apimeta.SetStatusCondition(obj.GetStatusConditions(), metav1.Condition{
+		Type:               condition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: obj.GetGeneration(),
+	})
+}
+
+// setPhaseConditions sets the Ready and Provisioning conditions to reflect
+// the given phase
+func setPhaseConditions(obj ObjectWithStatusConditions, phase, message string) {
+	switch phase {
+	case crunchybridgev1alpha1.PhaseUnknown, crunchybridgev1alpha1.PhasePending:
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonPending, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionTrue, ReasonPending, message)
+	case crunchybridgev1alpha1.PhaseCreating:
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonCreating, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionTrue, ReasonCreating, message)
+	case crunchybridgev1alpha1.PhaseUpdating:
+		// The cluster keeps serving while changes are applied
+		setStatusCondition(obj, ConditionReady, metav1.ConditionTrue, ReasonUpdating, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionTrue, ReasonUpdating, message)
+	case crunchybridgev1alpha1.PhaseUpgrading:
+		// Major upgrades restart the cluster on the new version
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonUpgrading, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionTrue, ReasonUpgrading, message)
+	case crunchybridgev1alpha1.PhaseReady:
+		setStatusCondition(obj, ConditionReady, metav1.ConditionTrue, ReasonAvailable, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionFalse, ReasonAvailable, message)
+	case crunchybridgev1alpha1.PhaseDeleting:
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonDeleting, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionFalse, ReasonDeleting, message)
+	case crunchybridgev1alpha1.PhaseFailed:
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonFailed, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionFalse, ReasonFailed, message)
+	case crunchybridgev1alpha1.PhaseStalled:
+		// Bridge may still finish provisioning a stalled cluster
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonStalled, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionTrue, ReasonStalled, message)
+	case crunchybridgev1alpha1.PhaseLost:
+		setStatusCondition(obj, ConditionReady, metav1.ConditionFalse, ReasonLost, message)
+		setStatusCondition(obj, ConditionProvisioning, metav1.ConditionFalse, ReasonLost, message)
+		setStatusCondition(obj, ConditionDegraded, metav1.ConditionTrue, ReasonLost, message)
+	}
+}
+
+// setErrorConditions reflects err in the BackendError and
+// AuthenticationError conditions, credential problems being distinguished
+// from other API failures. A nil err clears both conditions.
+func setErrorConditions(obj ObjectWithStatusConditions, err error) {
+	if err == nil {
+		setStatusCondition(obj, ConditionBackendError, metav1.ConditionFalse, ReasonAPIReachable, "")
+		setStatusCondition(obj, ConditionAuthenticationError, metav1.ConditionFalse, ReasonAuthenticated, "")
+		return
+	}
+
+	reason := errorReason(err)
+	if reason == ReasonAPIError {
+		setStatusCondition(obj, ConditionBackendError, metav1.ConditionTrue, reason, err.Error())
+	} else {
+		setStatusCondition(obj, ConditionAuthenticationError, metav1.ConditionTrue, reason, err.Error())
+	}
+}
+
+// errorReason classifies a non-nil API error for conditions and events
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, bridgeapi.ErrorInvalidCreds),
+		errors.Is(err, bridgeapi.ErrorUnauthorized):
+		return ReasonInvalidCredentials
+	case errors.Is(err, bridgeapi.ErrorNoCreds):
+		return ReasonMissingCredentials
+	case errors.Is(err, bridgeapi.ErrorUnstarted),
+		errors.Is(err, bridgeapi.ErrorFailedLogin),
+		errors.Is(err, bridgeapi.ErrorFailedRenew):
+		return ReasonLoginPending
+	}
+	return ReasonAPIError
+}
@@ -18,45 +18,360 @@ package crunchybridge
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+)
+
+const (
+	drFinalizer = "crunchybridge.com/databaserole-finalizer"
 )
 
 // DatabaseRoleReconciler reconciles a DatabaseRole object
 type DatabaseRoleReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Accounts *AccountClients
+	// APIReader reads objects bypassing the manager cache, the manager's
+	// API reader is used if unset
+	APIReader client.Reader
 }
 
 //+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=databaseroles,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=databaseroles/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=crunchybridge.crunchydata.com,resources=databaseroles/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the DatabaseRole object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *DatabaseRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	logger := log.FromContext(ctx)
+
+	roleObj := &crunchybridgev1alpha1.DatabaseRole{}
+	if err := r.Get(ctx, req.NamespacedName, roleObj); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Likely deleted before action or extra pass post-deletion, no-op
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Error fetching DatabaseRole object for reconciliation")
+		return ctrl.Result{}, err
+	}
+
+	bridgeClient, err := r.Accounts.ClientFor(ctx, roleObj.Spec.AccountRef)
+	if err != nil {
+		return r.recordError(ctx, roleObj, err)
+	}
+
+	if roleObj.DeletionTimestamp != nil && !roleObj.DeletionTimestamp.IsZero() {
+		// Role deletion request / process finalizer, the credential secret
+		// is owned by the DatabaseRole and left to garbage collection
+		if listContains(roleObj.Finalizers, drFinalizer) {
+			if name := roleObj.Status.RoleName; name != "" {
+				clusterID := roleCluster(roleObj)
+				logger.Info("deleting role", "cluster_id", clusterID, "role", name)
+				// Roles are recorded before they are created, so one may
+				// never have been
+				err := bridgeClient.DeleteRole(ctx, clusterID, name)
+				if err != nil && !errors.Is(err, bridgeapi.ErrorNotFound) {
+					return r.recordError(ctx, roleObj, err)
+				}
+				logger.Info("role deleted", "cluster_id", clusterID, "role", name)
+				r.Recorder.Eventf(roleObj, corev1.EventTypeNormal, ReasonDeleted,
+					"Deleted role %s from cluster %s", name, clusterID)
+			}
+			controllerutil.RemoveFinalizer(roleObj, drFinalizer)
+			if err := r.Update(ctx, roleObj); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	switch roleObj.Status.Phase {
+	case crunchybridgev1alpha1.PhaseUnknown:
+		// New object, add our finalizer
+		if !listContains(roleObj.Finalizers, drFinalizer) {
+			controllerutil.AddFinalizer(roleObj, drFinalizer)
+			if err := r.Update(ctx, roleObj); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		// Set pending phase after so any errors in setting finalizer
+		// don't advance state
+		roleObj.Status.Phase = crunchybridgev1alpha1.PhasePending
+		if err := r.updateStatus(ctx, roleObj); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Event(roleObj, corev1.EventTypeNormal, ReasonPending, "Role pending creation")
+
+	case crunchybridgev1alpha1.PhasePending:
+		role, err := r.createRole(ctx, bridgeClient, roleObj)
+		if errors.Is(err, errRoleExists) {
+			logger.Error(err, "role cannot be created")
+			r.Recorder.Event(roleObj, corev1.EventTypeWarning, ReasonConflict, err.Error())
+			setStatusCondition(roleObj, ConditionReady, metav1.ConditionFalse, ReasonConflict, err.Error())
+			if statusErr := r.Status().Update(ctx, roleObj); statusErr != nil {
+				logger.Error(statusErr, "Error in updating DatabaseRole status")
+			}
+			return ctrl.Result{}, err
+		} else if err != nil {
+			return r.recordError(ctx, roleObj, err)
+		}
+		if roleObj.Status.Created == "" {
+			logger.Info("role created", "cluster_id", roleObj.Status.ClusterID, "role", role.Name)
+			roleObj.Status.Created = time.Now().Format(time.RFC3339)
+			if err := r.updateStatus(ctx, roleObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(roleObj, corev1.EventTypeNormal, ReasonCreated,
+				"Created role %s on cluster %s", role.Name, roleObj.Status.ClusterID)
+		}
+
+		if err := r.writeCredentialSecret(ctx, roleObj, role); err != nil {
+			return ctrl.Result{}, err
+		}
 
-	// your logic here
+		roleObj.Status.Phase = crunchybridgev1alpha1.PhaseReady
+		if err := r.updateStatus(ctx, roleObj); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Eventf(roleObj, corev1.EventTypeNormal, ReasonAvailable,
+			"Role credentials written to secret %s", roleObj.Status.CredentialRef.Name)
+
+	case crunchybridgev1alpha1.PhaseReady:
+		// Restore the credential secret if it has gone missing
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{
+			Namespace: roleObj.Status.CredentialRef.Namespace,
+			Name:      roleObj.Status.CredentialRef.Name,
+		}
+		restored := false
+		if err := r.Get(ctx, key, secret); apierrors.IsNotFound(err) {
+			role, err := bridgeClient.Role(ctx, roleCluster(roleObj), roleObj.Status.RoleName)
+			if err != nil {
+				return r.recordError(ctx, roleObj, err)
+			}
+			logger.Info("restoring role credentials", "role", role.Name, "secret", key.Name)
+			if err := r.writeCredentialSecret(ctx, roleObj, role); err != nil {
+				return ctrl.Result{}, err
+			}
+			restored = true
+		} else if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Moving or renaming a created role is reported rather than acted
+		// upon
+		mismatch := verifyRoleSpec(roleObj)
+		cond := apimeta.FindStatusCondition(roleObj.Status.Conditions, ConditionReady)
+		reported := cond != nil && cond.Reason == ReasonSpecMismatch
+		if !restored && (mismatch != nil) == reported {
+			return ctrl.Result{}, nil
+		}
+		if err := r.updateStatus(ctx, roleObj); err != nil {
+			return ctrl.Result{}, err
+		}
+		if restored {
+			r.Recorder.Eventf(roleObj, corev1.EventTypeNormal, ReasonRestored,
+				"Restored missing credential secret %s", key.Name)
+		}
+		if mismatch != nil && !reported {
+			r.Recorder.Event(roleObj, corev1.EventTypeWarning, ReasonSpecMismatch, mismatch.Error())
+		}
+
+	default:
+		return ctrl.Result{}, fmt.Errorf("unrecognized phase: %s", roleObj.Status.Phase)
+	}
 
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DatabaseRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("databaserole-controller")
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&crunchybridgev1alpha1.DatabaseRole{}).
+		Owns(&corev1.Secret{}).
 		Complete(r)
 }
+
+// updateStatus writes back the status of roleObj, setting the conditions
+// which follow from its phase and clearing any earlier API error
+func (r *DatabaseRoleReconciler) updateStatus(ctx context.Context, roleObj *crunchybridgev1alpha1.DatabaseRole) error {
+	setPhaseConditions(roleObj, roleObj.Status.Phase, "")
+	if roleObj.Status.Phase == crunchybridgev1alpha1.PhaseReady {
+		if err := verifyRoleSpec(roleObj); err != nil {
+			setStatusCondition(roleObj, ConditionReady, metav1.ConditionFalse, ReasonSpecMismatch, err.Error())
+		}
+	}
+	setErrorConditions(roleObj, nil)
+	roleObj.Status.ObservedGeneration = roleObj.Generation
+	return r.Status().Update(ctx, roleObj)
+}
+
+// recordError reflects a Crunchy Bridge API error in the status conditions
+// and events of roleObj and passes it back for the request to be retried
+func (r *DatabaseRoleReconciler) recordError(ctx context.Context, roleObj *crunchybridgev1alpha1.DatabaseRole, err error) (ctrl.Result, error) {
+	r.Recorder.Event(roleObj, corev1.EventTypeWarning, errorReason(err), err.Error())
+	setErrorConditions(roleObj, err)
+	if statusErr := r.Status().Update(ctx, roleObj); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "Error in updating DatabaseRole status")
+	}
+	return ctrl.Result{}, err
+}
+
+// errRoleExists is returned by createRole when the requested name is taken
+var errRoleExists = errors.New("role already exists")
+
+// createRole creates the role requested by roleObj, recording it in the
+// status. An existing role of the requested name is refused rather than taken
+// over, it may belong to someone else and the finalizer would drop it along
+// with the DatabaseRole. Named roles are recorded before they are created so
+// that one created by a pass whose status write failed is recognised as
+// roleObj's own, unnamed roles are named by Bridge and recorded after.
+func (r *DatabaseRoleReconciler) createRole(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	roleObj *crunchybridgev1alpha1.DatabaseRole) (bridgeapi.ConnectionRole, error) {
+
+	if name := roleObj.Status.RoleName; name != "" {
+		role, err := bridgeClient.Role(ctx, roleCluster(roleObj), name)
+		if !errors.Is(err, bridgeapi.ErrorNotFound) {
+			return role, err
+		}
+		return r.createRecordedRole(ctx, bridgeClient, roleObj)
+	}
+
+	clusterID, name := roleObj.Spec.ClusterID, roleObj.Spec.RoleName
+	if name == "" {
+		role, err := bridgeClient.CreateRole(ctx, clusterID, "")
+		if err != nil {
+			return role, err
+		}
+		roleObj.Status.RoleName = role.Name
+		roleObj.Status.ClusterID = clusterID
+		if err := r.updateStatus(ctx, roleObj); err != nil {
+			// Unrecorded, the role would be left behind by the next pass
+			// creating another
+			if delErr := bridgeClient.DeleteRole(ctx, clusterID, role.Name); delErr != nil {
+				log.FromContext(ctx).Error(delErr, "Error in removing unrecorded role", "role", role.Name)
+			}
+			roleObj.Status.RoleName = ""
+			roleObj.Status.ClusterID = ""
+			return role, err
+		}
+		return role, nil
+	}
+
+	_, err := bridgeClient.Role(ctx, clusterID, name)
+	if err == nil {
+		return bridgeapi.ConnectionRole{}, fmt.Errorf("%w: %s on cluster %s", errRoleExists, name, clusterID)
+	} else if !errors.Is(err, bridgeapi.ErrorNotFound) {
+		return bridgeapi.ConnectionRole{}, err
+	}
+	roleObj.Status.RoleName = name
+	roleObj.Status.ClusterID = clusterID
+	if err := r.updateStatus(ctx, roleObj); err != nil {
+		return bridgeapi.ConnectionRole{}, err
+	}
+	return r.createRecordedRole(ctx, bridgeClient, roleObj)
+}
+
+// createRecordedRole creates the role recorded in the status of roleObj. A
+// role of that name created in the meantime by someone else is refused, and
+// the record dropped so that the finalizer leaves it alone.
+func (r *DatabaseRoleReconciler) createRecordedRole(
+	ctx context.Context,
+	bridgeClient bridgeapi.Interface,
+	roleObj *crunchybridgev1alpha1.DatabaseRole) (bridgeapi.ConnectionRole, error) {
+
+	clusterID, name := roleCluster(roleObj), roleObj.Status.RoleName
+	role, err := bridgeClient.CreateRole(ctx, clusterID, name)
+	if !errors.Is(err, bridgeapi.ErrorConflict) {
+		return role, err
+	}
+	roleObj.Status.RoleName = ""
+	roleObj.Status.ClusterID = ""
+	if err := r.updateStatus(ctx, roleObj); err != nil {
+		return role, err
+	}
+	return role, fmt.Errorf("%w: %s on cluster %s", errRoleExists, name, clusterID)
+}
+
+// roleCluster returns the cluster the role of roleObj was created on, roles
+// created before it was recorded are taken to be on the spec's cluster
+func roleCluster(roleObj *crunchybridgev1alpha1.DatabaseRole) string {
+	if roleObj.Status.ClusterID != "" {
+		return roleObj.Status.ClusterID
+	}
+	return roleObj.Spec.ClusterID
+}
+
+// verifyRoleSpec checks that the spec still describes the role recorded in
+// the status
+func verifyRoleSpec(roleObj *crunchybridgev1alpha1.DatabaseRole) error {
+	if id := roleCluster(roleObj); roleObj.Spec.ClusterID != id {
+		return fmt.Errorf("cluster_id changed to %s, role %s remains on cluster %s",
+			roleObj.Spec.ClusterID, roleObj.Status.RoleName, id)
+	}
+	if name := roleObj.Spec.RoleName; name != "" && name != roleObj.Status.RoleName {
+		return fmt.Errorf("role_name changed to %s, role %s is kept", name, roleObj.Status.RoleName)
+	}
+	return nil
+}
+
+// writeCredentialSecret creates or updates the secret holding the role
+// credentials and records its location in the DatabaseRole status
+func (r *DatabaseRoleReconciler) writeCredentialSecret(
+	ctx context.Context,
+	roleObj *crunchybridgev1alpha1.DatabaseRole,
+	role bridgeapi.ConnectionRole) error {
+
+	secret, err := newOwnedSecret(roleObj, r.Scheme, roleObj.Name+"-credentials", "DatabaseRole",
+		map[string][]byte{
+			"username": []byte(role.Name),
+			"password": []byte(role.Password),
+			"uri":      []byte(role.URI),
+		})
+	if err != nil {
+		return err
+	}
+	if err := applySecret(ctx, r.Client, r.APIReader, roleObj, secret); err != nil {
+		if errors.Is(err, errSecretConflict) {
+			r.Recorder.Event(roleObj, corev1.EventTypeWarning, ReasonSecretConflict, err.Error())
+		}
+		return err
+	}
+
+	roleObj.Status.CredentialRef = crunchybridgev1alpha1.NamespacedName{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+	}
+	return nil
+}
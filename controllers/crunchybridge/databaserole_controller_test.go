@@ -0,0 +1,210 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package crunchybridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crunchybridgev1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/crunchybridge/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/reconciletest"
+)
+
+// newRoleReconciler returns a DatabaseRoleReconciler using the account of
+// env by default
+func newRoleReconciler(env *reconciletest.Env) *DatabaseRoleReconciler {
+	return &DatabaseRoleReconciler{
+		Client:    env.Client,
+		Scheme:    env.Scheme,
+		Recorder:  env.Recorder,
+		Accounts:  &AccountClients{Reader: env.Client, Default: env.Bridge, APIURL: env.Server.APIURL()},
+		APIReader: env.Client,
+	}
+}
+
+func newRole(name, clusterID, roleName string) *crunchybridgev1alpha1.DatabaseRole {
+	return &crunchybridgev1alpha1.DatabaseRole{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: name},
+		Spec:       crunchybridgev1alpha1.DatabaseRoleSpec{ClusterID: clusterID, RoleName: roleName},
+	}
+}
+
+// roleReady is a condition for ReconcileUntil waiting on obj to be ready
+func roleReady(obj *crunchybridgev1alpha1.DatabaseRole) func(bool) bool {
+	return func(found bool) bool { return found && obj.Status.Phase == crunchybridgev1alpha1.PhaseReady }
+}
+
+// failingStatusClient fails the status writes fail selects
+type failingStatusClient struct {
+	client.Client
+	fail func(client.Object) bool
+}
+
+func (c failingStatusClient) Status() client.StatusWriter {
+	return failingStatusWriter{c.Client.Status(), c.fail}
+}
+
+type failingStatusWriter struct {
+	client.StatusWriter
+	fail func(client.Object) bool
+}
+
+func (w failingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if w.fail(obj) {
+		return errors.New("status write failed")
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func TestDatabaseRoleLifecycle(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newRoleReconciler(env)
+	clusterID := env.AddReadyCluster("roles")
+
+	obj := newRole("app", clusterID, "")
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, roleReady(obj))
+	role, err := env.Bridge.Role(env.Ctx, clusterID, obj.Status.RoleName)
+	if err != nil {
+		t.Fatalf("created role %q: %v", obj.Status.RoleName, err)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: "app-credentials"}}
+	if !env.Get(secret) {
+		t.Fatal("credential secret not written")
+	}
+	if string(secret.Data["username"]) != role.Name || string(secret.Data["password"]) != role.Password {
+		t.Errorf("credential secret = %q; want role %+v", secret.Data, role)
+	}
+
+	// Moving a created role is reported rather than acted upon
+	env.Modify(obj, func() { obj.Spec.RoleName = "renamed" })
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionReady).Reason == ReasonSpecMismatch
+	})
+	if _, err := env.Bridge.Role(env.Ctx, clusterID, "renamed"); err == nil || !env.Recorded(ReasonSpecMismatch) {
+		t.Errorf("renaming the role: %v", err)
+	}
+
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if _, err := env.Bridge.Role(env.Ctx, clusterID, role.Name); err == nil {
+		t.Errorf("role %s kept after deletion", role.Name)
+	}
+}
+
+func TestDatabaseRoleExisting(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newRoleReconciler(env)
+	clusterID := env.AddReadyCluster("roles")
+	if _, err := env.Bridge.CreateRole(env.Ctx, clusterID, "taken"); err != nil {
+		t.Fatal(err)
+	}
+
+	obj := newRole("taken", clusterID, "taken")
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return condition(obj, ConditionReady).Reason == ReasonConflict
+	})
+	if obj.Status.Phase != crunchybridgev1alpha1.PhasePending || obj.Status.RoleName != "" || !env.Recorded(ReasonConflict) {
+		t.Fatalf("existing role taken over in phase %q as %q", obj.Status.Phase, obj.Status.RoleName)
+	}
+
+	// The role isn't the DatabaseRole's to remove
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if _, err := env.Bridge.Role(env.Ctx, clusterID, "taken"); err != nil {
+		t.Errorf("existing role after deletion: %v", err)
+	}
+}
+
+func TestDatabaseRoleUnrecordedCreate(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	clusterID := env.AddReadyCluster("roles")
+
+	// A named role is recorded before it is created, and recognised as the
+	// DatabaseRole's own when writing the status after creating it fails
+	r := newRoleReconciler(env)
+	failed := false
+	r.Client = failingStatusClient{env.Client, func(obj client.Object) bool {
+		if role := obj.(*crunchybridgev1alpha1.DatabaseRole); !failed && role.Status.Created != "" {
+			failed = true
+			return true
+		}
+		return false
+	}}
+	named := newRole("named", clusterID, "named")
+	env.Create(named)
+	env.ReconcileUntil(r, named, roleReady(named))
+	if !failed || named.Status.RoleName != "named" || env.Recorded(ReasonConflict) {
+		t.Errorf("named role recovered as %q, status write failed %v", named.Status.RoleName, failed)
+	}
+
+	// Bridge names an unnamed role, one whose name can't be recorded is
+	// removed rather than left behind by creating another
+	var unrecorded string
+	r.Client = failingStatusClient{env.Client, func(obj client.Object) bool {
+		if role := obj.(*crunchybridgev1alpha1.DatabaseRole); unrecorded == "" && role.Status.RoleName != "" {
+			unrecorded = role.Status.RoleName
+			return true
+		}
+		return false
+	}}
+	unnamed := newRole("unnamed", clusterID, "")
+	env.Create(unnamed)
+	env.ReconcileUntil(r, unnamed, roleReady(unnamed))
+	if unrecorded == "" || unnamed.Status.RoleName == unrecorded {
+		t.Fatalf("unnamed role %q, unrecorded %q", unnamed.Status.RoleName, unrecorded)
+	}
+	if _, err := env.Bridge.Role(env.Ctx, clusterID, unrecorded); !errors.Is(err, bridgeapi.ErrorNotFound) {
+		t.Errorf("unrecorded role %s: %v; want it removed", unrecorded, err)
+	}
+}
+
+func TestDatabaseRoleSecretConflict(t *testing.T) {
+	env := reconciletest.New(t, crunchybridgev1alpha1.AddToScheme)
+	r := newRoleReconciler(env)
+	clusterID := env.AddReadyCluster("roles")
+	unowned := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: "app-credentials"},
+		Data:       map[string][]byte{"password": []byte("unrelated")},
+	}
+	env.Create(unowned)
+
+	obj := newRole("app", clusterID, "app")
+	env.Create(obj)
+	if err := env.ReconcileTimes(r, obj, 3); err == nil || obj.Status.Phase == crunchybridgev1alpha1.PhaseReady ||
+		!env.Recorded(ReasonSecretConflict) {
+		t.Errorf("unowned secret: %v in phase %q", err, obj.Status.Phase)
+	}
+	env.Get(unowned)
+	if string(unowned.Data["password"]) != "unrelated" {
+		t.Errorf("unowned secret overwritten: %q", unowned.Data)
+	}
+
+	// The created role is kept as the DatabaseRole's own once the secret
+	// is out of the way
+	env.Delete(unowned)
+	env.ReconcileUntil(r, obj, roleReady(obj))
+	if obj.Status.RoleName != "app" || env.Recorded(ReasonConflict) {
+		t.Errorf("role %q after the conflict cleared", obj.Status.RoleName)
+	}
+}
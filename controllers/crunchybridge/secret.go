@@ -0,0 +1,91 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crunchybridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newOwnedSecret returns an opaque secret in the owner's namespace carrying
+// the given data, with the owner set as its controller
+func newOwnedSecret(owner client.Object, scheme *runtime.Scheme, name, kind string, data map[string][]byte) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+			Labels: map[string]string{
+				"managed-by": "crunchy-bridge-operator",
+				"owner":      owner.GetName(),
+				"owner.kind": kind,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	if err := ctrl.SetControllerReference(owner, secret, scheme); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// errSecretConflict is returned by applySecret for a secret of the same name
+// which the owner doesn't control
+var errSecretConflict = errors.New("secret exists and is not managed by the operator")
+
+// applySecret creates the secret, or brings an existing secret of the same
+// name in line with it if owner controls it. Secrets created by others are
+// left alone, as are secrets already in line. The existing secret is read
+// through reader, as the secrets written here lack the labels the DBaaS build
+// limits the manager cache to.
+func applySecret(ctx context.Context, c client.Client, reader client.Reader, owner client.Object, secret *corev1.Secret) error {
+	existing := &corev1.Secret{}
+	err := reader.Get(ctx, client.ObjectKeyFromObject(secret), existing)
+	if apierrors.IsNotFound(err) {
+		return c.Create(ctx, secret)
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(existing, owner) {
+		return fmt.Errorf("%w: %s", errSecretConflict, secret.Name)
+	}
+
+	changed := !equality.Semantic.DeepEqual(existing.Data, secret.Data)
+	if existing.Labels == nil {
+		existing.Labels = map[string]string{}
+	}
+	for k, v := range secret.Labels {
+		if existing.Labels[k] != v {
+			existing.Labels[k] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	existing.Data = secret.Data
+	return c.Update(ctx, existing)
+}
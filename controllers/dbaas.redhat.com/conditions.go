@@ -16,8 +16,13 @@ limitations under the License.
 package dbaasredhatcom
 
 import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	dbaasredhatcomv1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/dbaas.redhat.com/v1alpha1"
 )
@@ -32,6 +37,14 @@ const (
 	ProvisionReady         string = "ProvisionReady"
 	Ready                  string = "Ready"
 	NotFound               string = "NotFound"
+	Deleted                string = "Deleted"
+	Failed                 string = "Failed"
+	Stalled                string = "Stalled"
+	Retrying               string = "Retrying"
+	DeletionStalled        string = "DeletionStalled"
+	ForceRemoved           string = "ForceRemoved"
+	Lost                   string = "Lost"
+	Recreating             string = "Recreating"
 	InstanceSuccessMessage string = "Successfully created crunchy bridge cluster"
 	SuccessMessage         string = "Successfully listed crunchy bridge Inventories"
 	SuccessConnection      string = "Successfully retrieved the connection detail\n"
@@ -58,6 +71,25 @@ func setStatusCondition(obj ObjectWithStatusConditions, condition string, status
 	apimeta.SetStatusCondition(conditions, newCondition)
 }
 
+// recordConditionEvent records an event for condition about to be set on obj
+// with the given status, reason and message, only when its status or reason
+// changes so that repeated reconciles don't flood the event stream. Failures
+// are recorded as Warning events.
+func recordConditionEvent(recorder record.EventRecorder, obj interface {
+	client.Object
+	ObjectWithStatusConditions
+}, condition string, status metav1.ConditionStatus, reason, message string) {
+	prev := apimeta.FindStatusCondition(*obj.GetStatusConditions(), condition)
+	if prev != nil && prev.Status == status && prev.Reason == reason {
+		return
+	}
+	eventType := corev1.EventTypeNormal
+	if status != metav1.ConditionTrue {
+		eventType = corev1.EventTypeWarning
+	}
+	recorder.Event(obj, eventType, reason, strings.TrimSpace(message))
+}
+
 // GetCondition return the condition with the passed condition type from
 // the status object. If the condition is not already present, return nil
 func GetConnectonCondition(inv *dbaasredhatcomv1alpha1.CrunchyBridgeConnection, condType string) *metav1.Condition {
@@ -44,13 +44,13 @@ const (
 )
 
 // connectionDetails
-func (r *CrunchyBridgeConnectionReconciler) connectionDetails(instanceID string, connection *dbaasredhatcomv1alpha1.CrunchyBridgeConnection, bridgeapi *bridgeapi.Client, req ctrl.Request, logger logr.Logger) error {
+func (r *CrunchyBridgeConnectionReconciler) connectionDetails(ctx context.Context, instanceID string, connection *dbaasredhatcomv1alpha1.CrunchyBridgeConnection, bridgeapi bridgeapi.Interface, req ctrl.Request, logger logr.Logger) error {
 
 	if r.isBindingExist(connection) {
 		return nil
 	}
 
-	connectionRole, err := bridgeapi.DefaultConnRole(instanceID)
+	connectionRole, err := bridgeapi.DefaultConnRole(ctx, instanceID)
 
 	if err != nil {
 		logger.Error(err, "Error in getting the connectionRole")
@@ -59,7 +59,7 @@ func (r *CrunchyBridgeConnectionReconciler) connectionDetails(instanceID string,
 
 	if connection.Status.CredentialsRef == nil {
 		secret := getOwnedSecret(connection, connectionRole.Name, connectionRole.Password)
-		err := r.Client.Create(context.Background(), secret, &client.CreateOptions{})
+		err := r.Client.Create(ctx, secret, &client.CreateOptions{})
 		if err != nil {
 			logger.Error(err, "Error in creating the secret")
 			return err
@@ -68,7 +68,7 @@ func (r *CrunchyBridgeConnectionReconciler) connectionDetails(instanceID string,
 	}
 	if connection.Status.ConnectionInfoRef == nil {
 		configMap := getOwnedConfigMap(connection, connectionRole.URI)
-		configMapCreated, err := r.Clientset.CoreV1().ConfigMaps(req.Namespace).Create(context.Background(), configMap, metav1.CreateOptions{})
+		configMapCreated, err := r.Clientset.CoreV1().ConfigMaps(req.Namespace).Create(ctx, configMap, metav1.CreateOptions{})
 		if err != nil {
 			logger.Error(err, "Error in creating the configMap")
 			return err
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -38,13 +39,18 @@ import (
 type CrunchyBridgeConnectionReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
 	Clientset  *kubernetes.Clientset
 	APIBaseURL string
+	// ClientFactory creates Crunchy Bridge API clients, defaults to
+	// logging in to APIBaseURL with the inventory credentials
+	ClientFactory ClientFactory
 }
 
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeconnections,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeconnections/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeconnections/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -93,7 +99,7 @@ func (r *CrunchyBridgeConnectionReconciler) Reconcile(ctx context.Context, req c
 		}
 		return ctrl.Result{}, err
 	}
-	bridgeapiClient, err := setupClient(r.Client, inventory, r.APIBaseURL, logger)
+	bridgeapiClient, err := newBridgeClient(r.ClientFactory, r.Client, inventory, r.APIBaseURL, logger)
 	if err != nil {
 		statusErr := r.updateStatus(ctx, connection, metav1.ConditionFalse, BackendError, err.Error())
 		if statusErr != nil {
@@ -103,9 +109,10 @@ func (r *CrunchyBridgeConnectionReconciler) Reconcile(ctx context.Context, req c
 		logger.Error(err, "Error while setting up CrunchyBridge Client")
 		return ctrl.Result{}, err
 	}
+	defer bridgeapiClient.Close()
 
 	logger.Info("Crunchy Bridge Client Configured ")
-	err = r.connectionDetails(instance.InstanceID, &connection, bridgeapiClient, req, logger)
+	err = r.connectionDetails(ctx, instance.InstanceID, &connection, bridgeapiClient, req, logger)
 	if err != nil {
 		statusErr := r.updateStatus(ctx, connection, metav1.ConditionFalse, BackendError, err.Error())
 		if statusErr != nil {
@@ -125,6 +132,9 @@ func (r *CrunchyBridgeConnectionReconciler) Reconcile(ctx context.Context, req c
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *CrunchyBridgeConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("crunchybridgeconnection-controller")
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dbaasredhatcomv1alpha1.CrunchyBridgeConnection{}).
 		Complete(r)
@@ -146,6 +156,7 @@ func getInstance(inventory *dbaasredhatcomv1alpha1.CrunchyBridgeInventory, insta
 
 // updateStatus
 func (r *CrunchyBridgeConnectionReconciler) updateStatus(ctx context.Context, connection dbaasredhatcomv1alpha1.CrunchyBridgeConnection, conidtionStatus metav1.ConditionStatus, reason, message string) error {
+	recordConditionEvent(r.Recorder, &connection, ReadyForBinding, conidtionStatus, reason, message)
 	setStatusCondition(&connection, ReadyForBinding, conidtionStatus, reason, message)
 	if err := r.Client.Status().Update(context.Background(), &connection); err != nil {
 		return err
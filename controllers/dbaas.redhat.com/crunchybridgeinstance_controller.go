@@ -21,39 +21,92 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	dbaasv1alpha1 "github.com/RHEcosystemAppEng/dbaas-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	dbaasredhatcomv1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/dbaas.redhat.com/v1alpha1"
 	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgepoll"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/lifecycle"
 )
 
 const (
 	instanceFinalizer = "dbaas.redhat.com/crunchybridgeinstance-finalizer"
-	WatchInt          = 10 * time.Second
 
-	PhaseBlank = ""
+	PhaseBlank   = ""
+	PhaseFailed  = "Failed"
+	PhaseStalled = "Stalled"
+	PhaseLost    = "Lost"
+
+	// DefaultPGMajorVersion is deployed when the PGMajorVer instance
+	// parameter is unset, matching the BridgeCluster default
+	DefaultPGMajorVersion = 14
+
+	// ProvisioningFailurePolicyFail leaves a cluster which failed or stalled
+	// during provisioning in place, ProvisioningFailurePolicyRetry deletes
+	// it and requests a new one once it is gone. Create requests Bridge
+	// rejected are never retried. Chosen by the ProvisioningFailurePolicy
+	// instance parameter
+	ProvisioningFailurePolicyFail  = "Fail"
+	ProvisioningFailurePolicyRetry = "Retry"
+
+	// RecoveryPolicyIgnore leaves an instance whose cluster was deleted
+	// outside the operator Lost, RecoveryPolicyRecreate provisions a
+	// replacement. Chosen by the RecoveryPolicy instance parameter
+	RecoveryPolicyIgnore   = "Ignore"
+	RecoveryPolicyRecreate = "Recreate"
+
+	// Instance info keys tracking provisioning, kept across updates from
+	// the cluster detail
+	PROVISIONING_STARTED  = "provisioning_started"
+	PROVISIONING_ATTEMPTS = "provisioning_attempts"
+	PROVISIONING_FAILED   = "provisioning_failed"
+	DELETION_STARTED      = "deletion_started"
+
+	// AnnotationForceRemove, when set to "true" on an instance being
+	// deleted, releases the finalizer without waiting for the cluster to be
+	// removed, for when the API can no longer be reached
+	AnnotationForceRemove = "crunchybridge.crunchydata.com/force-remove"
 )
 
 // CrunchyBridgeInstanceReconciler reconciles a CrunchyBridgeInstance object
 type CrunchyBridgeInstanceReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
 	APIBaseURL string
+	// ClientFactory creates Crunchy Bridge API clients, defaults to
+	// logging in to APIBaseURL with the inventory credentials
+	ClientFactory ClientFactory
+	// Pollers watch cluster state on behalf of the reconciler, one per
+	// inventory. A registry given here may be shared with other reconcilers
+	// and is started by the caller, one with default intervals is created
+	// and started if unset
+	Pollers *bridgepoll.Registry
+
+	events chan event.GenericEvent
 }
 
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeinstances,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeinstances/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeinstances/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -75,6 +128,11 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 		logger.Error(err, "Error fetching CrunchyBridgeInstance object for reconciliation")
 		return ctrl.Result{}, err
 	}
+	if !instanceObj.DeletionTimestamp.IsZero() && listContains(instanceObj.Finalizers, instanceFinalizer) &&
+		instanceObj.Annotations[AnnotationForceRemove] == "true" {
+		// Checked before any API access, which may be what is failing
+		return r.forceRemove(ctx, instanceObj)
+	}
 	inventory := dbaasredhatcomv1alpha1.CrunchyBridgeInventory{}
 	if err := r.Get(ctx, types.NamespacedName{Namespace: instanceObj.Spec.InventoryRef.Namespace, Name: instanceObj.Spec.InventoryRef.Name}, &inventory); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -89,7 +147,7 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 		logger.Error(err, "Error fetching CrunchyBridgeInstance object for reconciliation")
 		return ctrl.Result{}, err
 	}
-	bridgeapiClient, err := setupClient(r.Client, inventory, r.APIBaseURL, logger)
+	bridgeapiClient, err := newBridgeClient(r.ClientFactory, r.Client, inventory, r.APIBaseURL, logger)
 	if err != nil {
 		statusErr := r.updateStatus(instanceObj, metav1.ConditionFalse, AuthenticationError, err.Error())
 		if statusErr != nil {
@@ -99,30 +157,53 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 		logger.Error(err, "No CrunchyBridge client configured")
 		return ctrl.Result{}, err
 	}
+	defer bridgeapiClient.Close()
 	logger.Info("Crunchy Bridge Client Configured ")
+	poller := r.pollerFor(client.ObjectKeyFromObject(&inventory))
 
 	if instanceObj.DeletionTimestamp != nil && !instanceObj.DeletionTimestamp.IsZero() {
 		// Cluster deletion request / process finalizer
 		if listContains(instanceObj.Finalizers, instanceFinalizer) {
-			if id := instanceObj.Status.InstanceID; id != "" {
+			id := instanceObj.Status.InstanceID
+			switch {
+			case id == "":
+				// Nothing was created, nothing to clean up
+			case instanceObj.Status.Phase != dbaasv1alpha1.InstancePhaseDeleting:
 				logger.Info("deleting cluster", "id", id)
-				instanceObj.Status.Phase = dbaasv1alpha1.InstancePhaseDeleting
-				if err := r.Status().Update(ctx, instanceObj); err != nil {
-					if apierrors.IsConflict(err) {
-						logger.Info("Instance modified, retry reconciling")
-						return ctrl.Result{Requeue: true}, nil
-					}
-					logger.Error(err, "Failed to update Instance phase in status")
-					return ctrl.Result{}, err
-				}
-				if err := bridgeapiClient.DeleteCluster(id); err != nil {
+				err := bridgeapiClient.DeleteCluster(ctx, id)
+				switch {
+				case errors.Is(err, bridgeapi.ErrorNotFound):
+					logger.Info("cluster already removed", "id", id)
+					r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, Deleted, "Cluster %s already removed", id)
+				case err != nil:
 					logger.Error(err, "Failed to delete a cluster")
-
+					r.Recorder.Event(instanceObj, corev1.EventTypeWarning, BackendError, err.Error())
 					return ctrl.Result{}, err
+				default:
+					// Bridge tears the cluster down in the background, the
+					// finalizer stays until it is gone
+					instanceObj.Status.Phase = dbaasv1alpha1.InstancePhaseDeleting
+					setInstanceInfo(&instanceObj.Status, DELETION_STARTED, time.Now().Format(time.RFC3339))
+					if err := r.Status().Update(ctx, instanceObj); err != nil {
+						if apierrors.IsConflict(err) {
+							logger.Info("Instance modified, retry reconciling")
+							return ctrl.Result{Requeue: true}, nil
+						}
+						logger.Error(err, "Failed to update Instance phase in status")
+						return ctrl.Result{}, err
+					}
+					r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, string(dbaasv1alpha1.InstancePhaseDeleting),
+						"Deleting cluster %s", id)
+					poller.Track(r.events, instanceObj, id, instanceObj.Spec.Name, true)
+					return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, nil
+				}
+			default:
+				if result, gone, err := r.waitForRemoval(ctx, bridgeapiClient, poller, instanceObj); !gone {
+					return result, err
 				}
-				logger.Info("cluster deleted", "id", id)
 			}
 
+			poller.Untrack(r.events, instanceObj)
 			controllerutil.RemoveFinalizer(instanceObj, instanceFinalizer)
 			if err := r.Update(ctx, instanceObj); err != nil {
 				return ctrl.Result{}, err
@@ -148,16 +229,27 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 			if err := r.Status().Update(ctx, instanceObj); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.Recorder.Event(instanceObj, corev1.EventTypeNormal, string(dbaasv1alpha1.InstancePhasePending),
+				"Cluster pending creation")
 
 		case dbaasv1alpha1.InstancePhasePending:
-			req, err := r.createFromSpec(instanceObj.Spec, bridgeapiClient)
+			req, err := r.createFromSpec(ctx, instanceObj.Spec, bridgeapiClient)
 			if err != nil {
+				r.Recorder.Event(instanceObj, corev1.EventTypeWarning, BackendError, err.Error())
 				return ctrl.Result{}, err
 			}
 
 			logger.Info("cluster creation request", "request", req)
 
-			if err := bridgeapiClient.CreateCluster(req); err != nil {
+			attempts, _ := strconv.Atoi(instanceObj.Status.InstanceInfo[PROVISIONING_ATTEMPTS])
+			setInstanceInfo(&instanceObj.Status, PROVISIONING_ATTEMPTS, strconv.Itoa(attempts+1))
+			detC, err := bridgeapiClient.CreateCluster(ctx, req)
+			if err != nil {
+				// Bridge won't accept the same request later
+				if errors.Is(err, bridgeapi.ErrorBadRequest) {
+					return r.failProvisioning(ctx, poller, instanceObj, false,
+						fmt.Sprintf("create request rejected: %v", err))
+				}
 				statusErr := r.updateStatus(instanceObj, metav1.ConditionFalse, BackendError, err.Error())
 				if statusErr != nil {
 					logger.Error(statusErr, "Error in updating CrunchyBridgeInstance status")
@@ -166,28 +258,48 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 				return ctrl.Result{}, err
 			}
 
-			// Assuming the request was sent, update phase
+			// Following the new cluster by ID keeps an earlier one of the
+			// same name, such as one still being destroyed, from being
+			// mistaken for it
+			instanceObj.Status.InstanceID = detC.ID
 			instanceObj.Status.Phase = dbaasv1alpha1.InstancePhaseCreating
+			setInstanceInfo(&instanceObj.Status, PROVISIONING_STARTED, time.Now().Format(time.RFC3339))
 			if err := r.Status().Update(ctx, instanceObj); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, string(dbaasv1alpha1.InstancePhaseCreating),
+				"Requested creation of cluster %s", req.Name)
+			poller.Track(r.events, instanceObj, detC.ID, instanceObj.Spec.Name, true)
 
 		case dbaasv1alpha1.InstancePhaseCreating:
-			var detC bridgeapi.ClusterDetail
-			if cid := instanceObj.Status.InstanceID; cid == "" {
-				c, err := bridgeapiClient.ClusterByName(instanceObj.Spec.Name)
-				if err != nil {
-					return ctrl.Result{}, err
-				}
-				detC = c
-			} else {
-				c, err := bridgeapiClient.ClusterDetail(cid)
-				if err != nil {
-					return ctrl.Result{}, err
+			cid := instanceObj.Status.InstanceID
+			detC, found, err := poller.Lookup(cid, instanceObj.Spec.Name)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			// The poller sends the object back once the cluster is listed
+			// and whenever it changes while provisioning
+			remaining := lifecycle.Remaining(instanceObj.Status.InstanceInfo[PROVISIONING_STARTED], provisioningTimeout(instanceObj.Spec))
+			if !found {
+				if remaining <= 0 {
+					return r.failProvisioning(ctx, poller, instanceObj, true,
+						fmt.Sprintf("cluster %s not listed within %s", instanceObj.Spec.Name, provisioningTimeout(instanceObj.Spec)))
 				}
-				detC = c
+				poller.Track(r.events, instanceObj, cid, instanceObj.Spec.Name, true)
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+			logger.Info("cluster creating", "name", instanceObj.Spec.Name, "state", detC.State)
+
+			if bridgeapi.ClusterState(detC.State).ProvisioningFailed() {
+				instanceObj.Status.InstanceID = detC.ID
+				return r.failProvisioning(ctx, poller, instanceObj, false,
+					fmt.Sprintf("cluster %s reported state %q while provisioning", detC.ID, detC.State))
+			}
+			if detC.State != string(bridgeapi.StateReady) && remaining <= 0 {
+				instanceObj.Status.InstanceID = detC.ID
+				return r.failProvisioning(ctx, poller, instanceObj, true,
+					fmt.Sprintf("cluster %s still in state %q after %s", detC.ID, detC.State, provisioningTimeout(instanceObj.Spec)))
 			}
-			logger.Info("cluster creating", "name", instanceObj.Spec.Name)
 
 			if err := r.updateStatusFromDetail(detC, &instanceObj.Status); err != nil {
 				statusErr := r.updateStatus(instanceObj, metav1.ConditionFalse, BackendError, err.Error())
@@ -211,10 +323,132 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 			if err := r.Status().Update(ctx, instanceObj); err != nil {
 				return ctrl.Result{}, err
 			}
-			return ctrl.Result{Requeue: true, RequeueAfter: WatchInt}, nil
+			poller.Track(r.events, instanceObj, detC.ID, detC.Name,
+				instanceObj.Status.Phase == dbaasv1alpha1.InstancePhaseCreating)
+			if instanceObj.Status.Phase == dbaasv1alpha1.InstancePhaseCreating {
+				// The poller only reports changes, come back to check the
+				// timeout if the cluster sits in one state
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+
+		case PhaseFailed, PhaseStalled:
+			cid := instanceObj.Status.InstanceID
+			// A cluster being removed for a retry is seen through first
+			removing := cid != "" && instanceObj.Status.InstanceInfo[DELETION_STARTED] != ""
+			if instanceObj.Status.Phase == PhaseStalled && !removing {
+				// Bridge may yet finish provisioning a stalled cluster
+				detC, found, err := poller.Lookup(cid, instanceObj.Spec.Name)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				if found && bridgeapi.ClusterState(detC.State).ProvisioningFailed() {
+					instanceObj.Status.InstanceID = detC.ID
+					return r.failProvisioning(ctx, poller, instanceObj, false,
+						fmt.Sprintf("cluster %s reported state %q while provisioning", detC.ID, detC.State))
+				}
+				if found && detC.State == string(bridgeapi.StateReady) {
+					if err := r.updateStatusFromDetail(detC, &instanceObj.Status); err != nil {
+						return ctrl.Result{}, err
+					}
+					instanceObj.Status.Phase = dbaasv1alpha1.InstancePhaseReady
+					if err := r.updateStatus(instanceObj, metav1.ConditionTrue, Ready, InstanceSuccessMessage); err != nil {
+						return ctrl.Result{}, err
+					}
+					logger.Info("stalled cluster became ready", "name", instanceObj.Spec.Name)
+					poller.Track(r.events, instanceObj, detC.ID, detC.Name, false)
+					return ctrl.Result{}, nil
+				}
+			}
+
+			if !removing {
+				if instanceObj.Spec.OtherInstanceParams["ProvisioningFailurePolicy"] != ProvisioningFailurePolicyRetry {
+					return ctrl.Result{}, nil
+				}
+				// Failing without a cluster means Bridge rejected the create
+				// request, which it will do again
+				if instanceObj.Status.Phase == PhaseFailed && cid == "" {
+					return ctrl.Result{}, nil
+				}
+				attempts, _ := strconv.Atoi(instanceObj.Status.InstanceInfo[PROVISIONING_ATTEMPTS])
+				if wait := lifecycle.RetryWait(attempts, instanceObj.Status.InstanceInfo[PROVISIONING_FAILED]); wait > 0 {
+					return ctrl.Result{RequeueAfter: wait}, nil
+				}
+			}
+
+			if cid != "" {
+				// The old cluster keeps its name, and stays listed, until
+				// Bridge has destroyed it. Creating again before then would
+				// conflict or find the old cluster.
+				if !removing {
+					logger.Info("deleting cluster for provisioning retry", "id", cid)
+					if err := bridgeapiClient.DeleteCluster(ctx, cid); err != nil && !errors.Is(err, bridgeapi.ErrorNotFound) {
+						logger.Error(err, "Failed to delete a cluster")
+						r.Recorder.Event(instanceObj, corev1.EventTypeWarning, BackendError, err.Error())
+						return ctrl.Result{}, err
+					}
+					setInstanceInfo(&instanceObj.Status, DELETION_STARTED, time.Now().Format(time.RFC3339))
+					if err := r.Status().Update(ctx, instanceObj); err != nil {
+						return ctrl.Result{}, err
+					}
+					r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, string(dbaasv1alpha1.InstancePhaseDeleting),
+						"Deleting cluster %s to retry provisioning", cid)
+					poller.Track(r.events, instanceObj, cid, instanceObj.Spec.Name, true)
+					return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, nil
+				}
+				if _, err := bridgeapiClient.ClusterDetail(ctx, cid); err == nil {
+					return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, nil
+				} else if !errors.Is(err, bridgeapi.ErrorNotFound) {
+					logger.Error(err, "Failed to check cluster removal")
+					r.Recorder.Event(instanceObj, corev1.EventTypeWarning, BackendError, err.Error())
+					return ctrl.Result{}, err
+				}
+				r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, Deleted, "Deleted cluster %s to retry provisioning", cid)
+			}
+			poller.Untrack(r.events, instanceObj)
+
+			attempts := instanceObj.Status.InstanceInfo[PROVISIONING_ATTEMPTS]
+			instanceObj.Status.InstanceID = ""
+			instanceObj.Status.InstanceInfo = map[string]string{PROVISIONING_ATTEMPTS: attempts}
+			instanceObj.Status.Phase = dbaasv1alpha1.InstancePhasePending
+			if err := r.Status().Update(ctx, instanceObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, Retrying,
+				"Retrying provisioning after %s attempts", attempts)
 
 		case dbaasv1alpha1.InstancePhaseReady:
-			// TODO: Monitor changes, change state machine (phoenix)
+			cid := instanceObj.Status.InstanceID
+			// The poller sends the object back if the cluster disappears
+			poller.Track(r.events, instanceObj, cid, instanceObj.Spec.Name, false)
+			if cid == "" {
+				return ctrl.Result{}, nil
+			}
+			if _, _, err := lifecycle.Lookup(ctx, bridgeapiClient, poller, cid, instanceObj.Spec.Name); !errors.Is(err, bridgeapi.ErrorNotFound) {
+				return ctrl.Result{}, err
+			}
+			logger.Info("cluster lost", "id", cid, "name", instanceObj.Spec.Name)
+			instanceObj.Status.Phase = PhaseLost
+			if err := r.updateStatus(instanceObj, metav1.ConditionFalse, Lost,
+				fmt.Sprintf("cluster %s no longer exists in Crunchy Bridge", cid)); err != nil {
+				return ctrl.Result{}, err
+			}
+			poller.Untrack(r.events, instanceObj)
+
+		case PhaseLost:
+			if instanceObj.Spec.OtherInstanceParams["RecoveryPolicy"] != RecoveryPolicyRecreate {
+				return ctrl.Result{}, nil
+			}
+
+			lostID := instanceObj.Status.InstanceID
+			logger.Info("recreating lost cluster", "id", lostID, "name", instanceObj.Spec.Name)
+			instanceObj.Status.InstanceID = ""
+			instanceObj.Status.InstanceInfo = nil
+			instanceObj.Status.Phase = dbaasv1alpha1.InstancePhasePending
+			if err := r.Status().Update(ctx, instanceObj); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, Recreating,
+				"Recreating cluster %s per recovery policy", lostID)
 
 		default:
 			return ctrl.Result{}, fmt.Errorf("unrecognized phase: %s", instanceObj.Status.Phase)
@@ -227,11 +461,142 @@ func (r *CrunchyBridgeInstanceReconciler) Reconcile(ctx context.Context, req ctr
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *CrunchyBridgeInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("crunchybridgeinstance-controller")
+	}
+	if r.Pollers == nil {
+		r.Pollers = &bridgepoll.Registry{}
+		if err := mgr.Add(r.Pollers); err != nil {
+			return err
+		}
+	}
+	r.events = make(chan event.GenericEvent)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dbaasredhatcomv1alpha1.CrunchyBridgeInstance{}).
+		Watches(&source.Channel{Source: r.events}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
 
+// pollerFor returns the poller watching clusters with the credentials of
+// the inventory. The inventory is fetched again for each poll, so that
+// changes to its credentials are followed
+func (r *CrunchyBridgeInstanceReconciler) pollerFor(key types.NamespacedName) *bridgepoll.Poller {
+	return r.Pollers.For("CrunchyBridgeInventory/"+key.String(), func(ctx context.Context) (bridgeapi.Interface, func(), error) {
+		inventory := dbaasredhatcomv1alpha1.CrunchyBridgeInventory{}
+		if err := r.Get(ctx, key, &inventory); err != nil {
+			return nil, nil, err
+		}
+		bc, err := newBridgeClient(r.ClientFactory, r.Client, inventory, r.APIBaseURL, log.FromContext(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		return bc, func() { bc.Close() }, nil
+	})
+}
+
+// waitForRemoval checks whether the cluster of instanceObj, being deleted,
+// is gone from Crunchy Bridge. Until it is, progress is reported through the
+// ProvisionReady condition and the result schedules the next check.
+func (r *CrunchyBridgeInstanceReconciler) waitForRemoval(
+	ctx context.Context,
+	bridgeapiClient bridgeapi.Interface,
+	poller *bridgepoll.Poller,
+	instanceObj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance) (ctrl.Result, bool, error) {
+
+	logger := log.FromContext(ctx)
+	id := instanceObj.Status.InstanceID
+
+	removal, err := lifecycle.CheckRemoval(ctx, bridgeapiClient, id, instanceObj.Status.InstanceInfo[DELETION_STARTED],
+		deletionTimeout(instanceObj.Spec), AnnotationForceRemove)
+	if err != nil {
+		logger.Error(err, "Failed to check cluster removal")
+		if statusErr := r.updateStatus(instanceObj, metav1.ConditionFalse, BackendError, err.Error()); statusErr != nil {
+			logger.Error(statusErr, "Error in updating CrunchyBridgeInstance status")
+		}
+		return ctrl.Result{}, false, err
+	}
+	if removal.Gone {
+		logger.Info("cluster deleted", "id", id)
+		r.Recorder.Eventf(instanceObj, corev1.EventTypeNormal, Deleted, "Deleted cluster %s", id)
+		return ctrl.Result{}, true, nil
+	}
+
+	reason := string(dbaasv1alpha1.InstancePhaseDeleting)
+	if removal.Stalled {
+		reason = DeletionStalled
+	}
+	if cond := apimeta.FindStatusCondition(instanceObj.Status.Conditions, ProvisionReady); cond == nil || cond.Message != removal.Message {
+		logger.Info("cluster deleting", "id", id, "stalled", removal.Stalled)
+		if err := r.updateStatus(instanceObj, metav1.ConditionFalse, reason, removal.Message); err != nil {
+			return ctrl.Result{}, false, err
+		}
+	}
+	poller.Track(r.events, instanceObj, id, instanceObj.Spec.Name, true)
+	return ctrl.Result{RequeueAfter: lifecycle.DeletionPollInterval}, false, nil
+}
+
+// forceRemove releases the finalizer of instanceObj, leaving whatever
+// remains of its cluster in Crunchy Bridge
+func (r *CrunchyBridgeInstanceReconciler) forceRemove(ctx context.Context, instanceObj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance) (ctrl.Result, error) {
+	inventoryKey := types.NamespacedName{Namespace: instanceObj.Spec.InventoryRef.Namespace, Name: instanceObj.Spec.InventoryRef.Name}
+	r.pollerFor(inventoryKey).Untrack(r.events, instanceObj)
+	return ctrl.Result{}, lifecycle.ForceRemove(ctx, r.Client, r.Recorder, instanceObj, instanceFinalizer,
+		instanceObj.Status.InstanceID, ForceRemoved, AnnotationForceRemove)
+}
+
+// deletionTimeout returns how long the cluster of an instance may take to be
+// removed, from the DeletionTimeout instance parameter (e.g. 30m, 2h)
+func deletionTimeout(spec dbaasv1alpha1.DBaaSInstanceSpec) time.Duration {
+	if d, err := time.ParseDuration(spec.OtherInstanceParams["DeletionTimeout"]); err == nil && d > 0 {
+		return d
+	}
+	return lifecycle.DefaultDeletionTimeout
+}
+
+// failProvisioning moves instanceObj to the Stalled phase if stalled is set,
+// otherwise Failed, with the explanation in message. The phase decides on
+// any retry in a later pass.
+func (r *CrunchyBridgeInstanceReconciler) failProvisioning(
+	ctx context.Context,
+	poller *bridgepoll.Poller,
+	instanceObj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance,
+	stalled bool,
+	message string) (ctrl.Result, error) {
+
+	reason := Failed
+	instanceObj.Status.Phase = PhaseFailed
+	if stalled {
+		reason = Stalled
+		instanceObj.Status.Phase = PhaseStalled
+	}
+	log.FromContext(ctx).Info("cluster provisioning "+strings.ToLower(reason),
+		"name", instanceObj.Spec.Name, "message", message)
+	setInstanceInfo(&instanceObj.Status, PROVISIONING_FAILED, time.Now().Format(time.RFC3339))
+	if err := r.updateStatus(instanceObj, metav1.ConditionFalse, reason, message); err != nil {
+		return ctrl.Result{}, err
+	}
+	lifecycle.FollowFailure(poller, r.events, instanceObj, instanceObj.Status.InstanceID, instanceObj.Spec.Name, stalled)
+	return ctrl.Result{}, nil
+}
+
+// provisioningTimeout returns how long an instance may spend provisioning,
+// from the ProvisioningTimeout instance parameter (e.g. 30m, 2h)
+func provisioningTimeout(spec dbaasv1alpha1.DBaaSInstanceSpec) time.Duration {
+	if d, err := time.ParseDuration(spec.OtherInstanceParams["ProvisioningTimeout"]); err == nil && d > 0 {
+		return d
+	}
+	return lifecycle.DefaultProvisioningTimeout
+}
+
+// setInstanceInfo sets key in the instance info of statusObj
+func setInstanceInfo(statusObj *dbaasv1alpha1.DBaaSInstanceStatus, key, value string) {
+	if statusObj.InstanceInfo == nil {
+		statusObj.InstanceInfo = map[string]string{}
+	}
+	statusObj.InstanceInfo[key] = value
+}
+
 func listContains(list []string, s string) bool {
 	for _, str := range list {
 		if str == s {
@@ -241,10 +606,10 @@ func listContains(list []string, s string) bool {
 	return false
 }
 
-func (r *CrunchyBridgeInstanceReconciler) createFromSpec(spec dbaasv1alpha1.DBaaSInstanceSpec, bridgeapiClient *bridgeapi.Client) (bridgeapi.CreateRequest, error) {
+func (r *CrunchyBridgeInstanceReconciler) createFromSpec(ctx context.Context, spec dbaasv1alpha1.DBaaSInstanceSpec, bridgeapiClient bridgeapi.Interface) (bridgeapi.CreateRequest, error) {
 	req := bridgeapi.CreateRequest{
 		Name:           spec.Name,
-		PGMajorVersion: 13,
+		PGMajorVersion: DefaultPGMajorVersion,
 		Plan:           "trial",
 		Provider:       spec.CloudProvider,
 		Region:         spec.CloudRegion,
@@ -254,15 +619,16 @@ func (r *CrunchyBridgeInstanceReconciler) createFromSpec(spec dbaasv1alpha1.DBaa
 		req.TeamID = teamID
 	} else {
 		// Lookup TeamID
-		if id, err := bridgeapiClient.DefaultTeamID(); err != nil {
+		if id, err := bridgeapiClient.DefaultTeamID(ctx); err != nil {
 			return req, err
 		} else {
 			req.TeamID = id
 		}
 	}
 
-	if majorVersion, ok := spec.OtherInstanceParams["PGMajorVer"]; ok {
-		req.PGMajorVersion = convertInt(majorVersion)
+	// An empty or invalid version keeps the default
+	if majorVersion := convertInt(spec.OtherInstanceParams["PGMajorVer"]); majorVersion > 0 {
+		req.PGMajorVersion = majorVersion
 	}
 
 	if plan, ok := spec.OtherInstanceParams["Plan"]; ok {
@@ -318,6 +684,7 @@ func (r *CrunchyBridgeInstanceReconciler) updateStatusFromDetail(
 		return errors.New("received cluster detail with no ID")
 	}
 	statusObj.InstanceID = det.ID
+	prev := statusObj.InstanceInfo
 	statusObj.InstanceInfo = map[string]string{
 		CLUSTER_NAME:  det.Name,
 		TEAM_ID:       det.TeamID,
@@ -329,12 +696,18 @@ func (r *CrunchyBridgeInstanceReconciler) updateStatusFromDetail(
 		PROVIDER_ID:   det.ProviderID,
 		REGION_ID:     det.RegionID,
 	}
+	for _, key := range []string{PROVISIONING_STARTED, PROVISIONING_ATTEMPTS, PROVISIONING_FAILED} {
+		if value, ok := prev[key]; ok {
+			statusObj.InstanceInfo[key] = value
+		}
+	}
 
 	return nil
 }
 
 // updateStatus
 func (r *CrunchyBridgeInstanceReconciler) updateStatus(instanceObj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance, conidtionStatus metav1.ConditionStatus, reason, message string) error {
+	recordConditionEvent(r.Recorder, instanceObj, ProvisionReady, conidtionStatus, reason, message)
 	setStatusCondition(instanceObj, ProvisionReady, conidtionStatus, reason, message)
 	if err := r.Client.Status().Update(context.Background(), instanceObj); err != nil {
 		return err
@@ -0,0 +1,232 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package dbaasredhatcom
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	dbaasv1alpha1 "github.com/RHEcosystemAppEng/dbaas-operator/api/v1alpha1"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dbaasredhatcomv1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/dbaas.redhat.com/v1alpha1"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/reconciletest"
+)
+
+// newInstanceReconciler returns a CrunchyBridgeInstanceReconciler whose
+// client factory gives out clients of the env account, along with the
+// inventory instances refer to
+func newInstanceReconciler(env *reconciletest.Env) *CrunchyBridgeInstanceReconciler {
+	env.Create(&dbaasredhatcomv1alpha1.CrunchyBridgeInventory{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: "inventory"},
+	})
+	return &CrunchyBridgeInstanceReconciler{
+		Client:   env.Client,
+		Scheme:   env.Scheme,
+		Recorder: env.Recorder,
+		ClientFactory: func(client.Client, dbaasredhatcomv1alpha1.CrunchyBridgeInventory, string, logr.Logger) (bridgeapi.Interface, error) {
+			return env.NewBridgeClient()
+		},
+		Pollers: env.Pollers,
+		events:  env.Events,
+	}
+}
+
+// newTestInstance returns an instance asking for a cluster named name with
+// the given instance parameters
+func newTestInstance(name string, params map[string]string) *dbaasredhatcomv1alpha1.CrunchyBridgeInstance {
+	return &dbaasredhatcomv1alpha1.CrunchyBridgeInstance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: reconciletest.Namespace, Name: name},
+		Spec: dbaasv1alpha1.DBaaSInstanceSpec{
+			InventoryRef:        dbaasv1alpha1.NamespacedName{Namespace: reconciletest.Namespace, Name: "inventory"},
+			Name:                name,
+			CloudProvider:       "aws",
+			CloudRegion:         "us-east-1",
+			OtherInstanceParams: params,
+		},
+	}
+}
+
+// inInstancePhase returns a condition for ReconcileUntil waiting on obj to
+// reach phase
+func inInstancePhase(obj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance, phase dbaasv1alpha1.DBaasInstancePhase) func(bool) bool {
+	return func(found bool) bool { return found && obj.Status.Phase == phase }
+}
+
+// readyInstance creates obj and reconciles it until its cluster is ready
+func readyInstance(env *reconciletest.Env, r *CrunchyBridgeInstanceReconciler, obj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance) {
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseCreating))
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseReady))
+}
+
+// instanceCondition returns the ProvisionReady condition of obj
+func instanceCondition(obj *dbaasredhatcomv1alpha1.CrunchyBridgeInstance) metav1.Condition {
+	if cond := GetIInstanceCondition(obj, ProvisionReady); cond != nil {
+		return *cond
+	}
+	return metav1.Condition{}
+}
+
+func TestInstanceLifecycle(t *testing.T) {
+	env := reconciletest.New(t, dbaasredhatcomv1alpha1.AddToScheme)
+	r := newInstanceReconciler(env)
+	obj := newTestInstance("lifecycle", nil)
+	readyInstance(env, r, obj)
+
+	dets := env.ClustersNamed("lifecycle")
+	if len(dets) != 1 || obj.Status.InstanceID != dets[0].ID {
+		t.Fatalf("instance cluster %q, Bridge has %+v", obj.Status.InstanceID, dets)
+	}
+	if cond := instanceCondition(obj); cond.Status != metav1.ConditionTrue {
+		t.Errorf("ProvisionReady = %+v", cond)
+	}
+
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if dets := env.ClustersNamed("lifecycle"); len(dets) != 0 {
+		t.Errorf("clusters after deletion = %+v", dets)
+	}
+}
+
+func TestInstanceProvisioningRetry(t *testing.T) {
+	env := reconciletest.New(t, dbaasredhatcomv1alpha1.AddToScheme)
+	r := newInstanceReconciler(env)
+	// Deleted clusters stay listed, destroying, under their name for a while
+	env.Server.SetDeletionTime(time.Hour)
+
+	obj := newTestInstance("retried", map[string]string{"ProvisioningFailurePolicy": ProvisioningFailurePolicyRetry})
+	env.Create(obj)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseCreating))
+	failedID := obj.Status.InstanceID
+	if failedID == "" {
+		t.Fatal("created cluster ID not recorded")
+	}
+
+	env.Server.SetClusterState(failedID, bridgeapi.StateFailed)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, PhaseFailed))
+
+	// Skip the backoff
+	env.Modify(obj, func() {
+		setInstanceInfo(&obj.Status, PROVISIONING_FAILED, time.Now().Add(-2*time.Hour).Format(time.RFC3339))
+	})
+	env.ReconcileUntil(r, obj, func(bool) bool { return obj.Status.InstanceInfo[DELETION_STARTED] != "" })
+
+	// While the old cluster is destroying, nothing is created in its place
+	if err := env.ReconcileTimes(r, obj, 10); err != nil {
+		t.Errorf("reconciling while the old cluster is destroyed: %v", err)
+	}
+	dets := env.ClustersNamed("retried")
+	if obj.Status.Phase != PhaseFailed || obj.Status.InstanceInfo[PROVISIONING_ATTEMPTS] != "1" ||
+		len(dets) != 1 || dets[0].State != string(bridgeapi.StateDestroying) {
+		t.Fatalf("while destroying: phase %q after %s attempts, Bridge has %+v",
+			obj.Status.Phase, obj.Status.InstanceInfo[PROVISIONING_ATTEMPTS], dets)
+	}
+
+	env.Server.Advance(time.Hour)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseCreating))
+	if obj.Status.InstanceID == "" || obj.Status.InstanceID == failedID || obj.Status.InstanceInfo[PROVISIONING_ATTEMPTS] != "2" {
+		t.Fatalf("retry created cluster %q after %s attempts; want a new one",
+			obj.Status.InstanceID, obj.Status.InstanceInfo[PROVISIONING_ATTEMPTS])
+	}
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseReady))
+}
+
+func TestInstanceRejectedCreate(t *testing.T) {
+	env := reconciletest.New(t, dbaasredhatcomv1alpha1.AddToScheme)
+	r := newInstanceReconciler(env)
+	obj := newTestInstance("rejected", map[string]string{"ProvisioningFailurePolicy": ProvisioningFailurePolicyRetry})
+	env.Create(obj)
+	env.Server.InjectFault(bridgetest.Fault{Method: "POST", Path: "/clusters", Status: 400})
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, PhaseFailed))
+	if obj.Status.InstanceID != "" || !env.Recorded(Failed) {
+		t.Fatalf("rejected create left cluster %q", obj.Status.InstanceID)
+	}
+
+	// Bridge would reject the same request again, it isn't retried
+	env.Modify(obj, func() {
+		setInstanceInfo(&obj.Status, PROVISIONING_FAILED, time.Now().Add(-2*time.Hour).Format(time.RFC3339))
+	})
+	env.ReconcileTimes(r, obj, 3)
+	if obj.Status.Phase != PhaseFailed || obj.Status.InstanceInfo[PROVISIONING_ATTEMPTS] != "1" {
+		t.Errorf("rejected create in phase %q after %s attempts; want Failed after 1",
+			obj.Status.Phase, obj.Status.InstanceInfo[PROVISIONING_ATTEMPTS])
+	}
+}
+
+func TestInstanceDeletionTimeout(t *testing.T) {
+	env := reconciletest.New(t, dbaasredhatcomv1alpha1.AddToScheme)
+	r := newInstanceReconciler(env)
+	env.Server.SetDeletionTime(time.Hour)
+	obj := newTestInstance("stuck", map[string]string{"DeletionTimeout": "10m"})
+	readyInstance(env, r, obj)
+	id := obj.Status.InstanceID
+
+	env.Delete(obj)
+	env.ReconcileUntil(r, obj, func(bool) bool {
+		return instanceCondition(obj).Reason == string(dbaasv1alpha1.InstancePhaseDeleting)
+	})
+	if len(obj.Finalizers) == 0 {
+		t.Fatal("finalizer released while the cluster is destroying")
+	}
+
+	// The DeletionTimeout parameter applies rather than the default
+	env.Modify(obj, func() {
+		setInstanceInfo(&obj.Status, DELETION_STARTED, time.Now().Add(-15*time.Minute).Format(time.RFC3339))
+	})
+	env.ReconcileUntil(r, obj, func(bool) bool { return instanceCondition(obj).Reason == DeletionStalled })
+	if cond := instanceCondition(obj); !strings.Contains(cond.Message, AnnotationForceRemove) {
+		t.Errorf("stalled deletion message %q doesn't mention %s", cond.Message, AnnotationForceRemove)
+	}
+
+	env.Modify(obj, func() {
+		obj.Annotations = map[string]string{AnnotationForceRemove: "true"}
+	})
+	env.ReconcileUntil(r, obj, func(found bool) bool { return !found })
+	if _, ok := env.Server.Cluster(id); !ok || !env.Recorded(ForceRemoved) {
+		t.Errorf("force removal: cluster kept %v, %s event %v", ok, ForceRemoved, env.Recorded(ForceRemoved))
+	}
+}
+
+func TestInstanceLost(t *testing.T) {
+	env := reconciletest.New(t, dbaasredhatcomv1alpha1.AddToScheme)
+	r := newInstanceReconciler(env)
+	obj := newTestInstance("lost", map[string]string{"RecoveryPolicy": RecoveryPolicyRecreate})
+	readyInstance(env, r, obj)
+	lostID := obj.Status.InstanceID
+
+	if err := env.Bridge.DeleteCluster(env.Ctx, lostID); err != nil {
+		t.Fatal(err)
+	}
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, PhaseLost))
+	if !env.Recorded(Lost) {
+		t.Errorf("no %s event", Lost)
+	}
+
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseCreating))
+	if obj.Status.InstanceID == "" || obj.Status.InstanceID == lostID || !env.Recorded(Recreating) {
+		t.Fatalf("recreated cluster %q; want a new one", obj.Status.InstanceID)
+	}
+	env.Server.Advance(bridgetest.DefaultProvisionTime)
+	env.ReconcileUntil(r, obj, inInstancePhase(obj, dbaasv1alpha1.InstancePhaseReady))
+}
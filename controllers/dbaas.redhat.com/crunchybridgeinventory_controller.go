@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -40,14 +41,19 @@ import (
 type CrunchyBridgeInventoryReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
 	APIBaseURL string
 	Log        logr.Logger
+	// ClientFactory creates Crunchy Bridge API clients, defaults to
+	// logging in to APIBaseURL with the inventory credentials
+	ClientFactory ClientFactory
 }
 
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeinventories,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeinventories/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=dbaas.redhat.com,resources=crunchybridgeinventories/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -73,7 +79,7 @@ func (r *CrunchyBridgeInventoryReconciler) Reconcile(ctx context.Context, req ct
 		return ctrl.Result{}, err
 	}
 
-	bridgeapiClient, err := setupClient(r.Client, inventory, r.APIBaseURL, logger)
+	bridgeapiClient, err := newBridgeClient(r.ClientFactory, r.Client, inventory, r.APIBaseURL, logger)
 	if err != nil {
 		statusErr := r.updateStatus(ctx, inventory, metav1.ConditionFalse, AuthenticationError, err.Error())
 		if statusErr != nil {
@@ -83,8 +89,9 @@ func (r *CrunchyBridgeInventoryReconciler) Reconcile(ctx context.Context, req ct
 		logger.Error(err, "Error while setting up CrunchyBridge Client")
 		return ctrl.Result{}, err
 	}
+	defer bridgeapiClient.Close()
 	logger.Info("Crunchy Bridge Client Configured ")
-	err = r.discoverInventories(&inventory, bridgeapiClient, logger)
+	err = r.discoverInventories(ctx, &inventory, bridgeapiClient, logger)
 	if err != nil {
 		statusErr := r.updateStatus(ctx, inventory, metav1.ConditionFalse, BackendError, err.Error())
 		if statusErr != nil {
@@ -102,8 +109,22 @@ func (r *CrunchyBridgeInventoryReconciler) Reconcile(ctx context.Context, req ct
 	return ctrl.Result{}, nil
 }
 
-// setupClient
-func setupClient(client client.Client, inventory dbaasredhatcomv1alpha1.CrunchyBridgeInventory, APIBaseURL string, logger logr.Logger) (*bridgeapi.Client, error) {
+// ClientFactory creates the Crunchy Bridge API client used to act on behalf
+// of an inventory, allowing tests to substitute an in-memory implementation
+type ClientFactory func(client client.Client, inventory dbaasredhatcomv1alpha1.CrunchyBridgeInventory, APIBaseURL string, logger logr.Logger) (bridgeapi.Interface, error)
+
+// newBridgeClient creates a client through factory, or through setupClient
+// when no factory is configured
+func newBridgeClient(factory ClientFactory, client client.Client, inventory dbaasredhatcomv1alpha1.CrunchyBridgeInventory, APIBaseURL string, logger logr.Logger) (bridgeapi.Interface, error) {
+	if factory == nil {
+		factory = setupClient
+	}
+	return factory(client, inventory, APIBaseURL, logger)
+}
+
+// setupClient logs in to APIBaseURL with the credentials held in the secret
+// referenced by the inventory
+func setupClient(client client.Client, inventory dbaasredhatcomv1alpha1.CrunchyBridgeInventory, APIBaseURL string, logger logr.Logger) (bridgeapi.Interface, error) {
 	baseUrl, err := url.Parse(APIBaseURL)
 	if err != nil {
 		logger.Error(err, "Malformed URL", "URL", APIBaseURL)
@@ -117,15 +138,20 @@ func setupClient(client client.Client, inventory dbaasredhatcomv1alpha1.CrunchyB
 		SecretField: SECRETFIELDNAME,
 	}
 
-	return bridgeapi.NewClient(baseUrl,
+	bridgeClient, err := bridgeapi.NewClient(baseUrl,
 		kubeSecretProvider,
 		bridgeapi.SetLogger(logger),
 		bridgeapi.SetVersion("0.0.2"), // hard coded for now to minimize change
 	)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeClient, nil
 }
 
 // updateStatus
 func (r *CrunchyBridgeInventoryReconciler) updateStatus(ctx context.Context, inventory dbaasredhatcomv1alpha1.CrunchyBridgeInventory, conidtionStatus metav1.ConditionStatus, reason, message string) error {
+	recordConditionEvent(r.Recorder, &inventory, SpecSynced, conidtionStatus, reason, message)
 	setStatusCondition(&inventory, SpecSynced, conidtionStatus, reason, message)
 	if err := r.Status().Update(ctx, &inventory); err != nil {
 		return err
@@ -137,6 +163,9 @@ func (r *CrunchyBridgeInventoryReconciler) updateStatus(ctx context.Context, inv
 func (r *CrunchyBridgeInventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	log := r.Log.WithValues("during", "CrunchyBridgeInventoryReconciler SetupWithManager")
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("crunchybridgeinventory-controller")
+	}
 
 	mapFn := handler.MapFunc(func(a client.Object) []ctrl.Request {
 		if instance, ok := a.(*dbaasredhatcomv1alpha1.CrunchyBridgeInstance); ok {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	dbaasoperator "github.com/RHEcosystemAppEng/dbaas-operator/api/v1alpha1"
@@ -230,7 +231,7 @@ func bridgeProviderCR(clusterRoleList *rbac.ClusterRoleList) *dbaasoperator.DBaa
 					DisplayName:  "Version",
 					Type:         "int",
 					Required:     true,
-					DefaultValue: "13",
+					DefaultValue: strconv.Itoa(DefaultPGMajorVersion),
 				},
 				{
 					Name:         "Provider",
@@ -266,6 +267,34 @@ func bridgeProviderCR(clusterRoleList *rbac.ClusterRoleList) *dbaasoperator.DBaa
 					Type:        "bool",
 					Required:    false,
 				},
+				{
+					Name:         "ProvisioningTimeout",
+					DisplayName:  "Provisioning Timeout",
+					Type:         "string",
+					Required:     false,
+					DefaultValue: "1h",
+				},
+				{
+					Name:         "DeletionTimeout",
+					DisplayName:  "Deletion Timeout",
+					Type:         "string",
+					Required:     false,
+					DefaultValue: "30m",
+				},
+				{
+					Name:         "ProvisioningFailurePolicy",
+					DisplayName:  "Provisioning Failure Policy",
+					Type:         "string",
+					Required:     false,
+					DefaultValue: ProvisioningFailurePolicyFail,
+				},
+				{
+					Name:         "RecoveryPolicy",
+					DisplayName:  "Recovery Policy",
+					Type:         "string",
+					Required:     false,
+					DefaultValue: RecoveryPolicyIgnore,
+				},
 			},
 		},
 	}
@@ -1,6 +1,7 @@
 package dbaasredhatcom
 
 import (
+	"context"
 	"strconv"
 
 	dbaasv1alpha1 "github.com/RHEcosystemAppEng/dbaas-operator/api/v1alpha1"
@@ -23,12 +24,14 @@ const (
 	IS_HA         = "is_ha"
 	CLUSTER_NAME  = "name"
 	STATE         = "state"
+	HOST          = "host"
+	REPLICA_OF    = "replica_of"
 )
 
 // discoverInventories query crunchy bridge and return list of inverntories by team
-func (r *CrunchyBridgeInventoryReconciler) discoverInventories(dbaasredhatcomv1alpha1 *dbaasredhatcomv1alpha1.CrunchyBridgeInventory, bridgeapi *bridgeapi.Client, logger logr.Logger) error {
+func (r *CrunchyBridgeInventoryReconciler) discoverInventories(ctx context.Context, dbaasredhatcomv1alpha1 *dbaasredhatcomv1alpha1.CrunchyBridgeInventory, bridgeapi bridgeapi.Interface, logger logr.Logger) error {
 	var bridgeInstances []dbaasv1alpha1.Instance
-	clusterList, clusterListErr := bridgeapi.ListAllClusters()
+	clusterList, clusterListErr := bridgeapi.ListAllClusters(ctx)
 	if clusterListErr != nil {
 		logger.Error(clusterListErr, "Error Listing the instance")
 		return clusterListErr
@@ -40,27 +43,39 @@ func (r *CrunchyBridgeInventoryReconciler) discoverInventories(dbaasredhatcomv1a
 		return nil
 	}
 	for _, cluster := range clusterList.Clusters {
-		clusterSvc := dbaasv1alpha1.Instance{
-			InstanceID: cluster.ID,
-			Name:       cluster.Name,
-			InstanceInfo: map[string]string{
-				TEAM_ID:       cluster.TeamID,
-				PROVIDER_ID:   cluster.ProviderID,
-				REGION_ID:     cluster.RegionID,
-				CREATED_AT:    cluster.Created.String(),
-				UPDATED_AT:    cluster.Updated.String(),
-				MAJOR_VERSION: strconv.Itoa(cluster.PGMajorVersion),
-				STORAGE:       strconv.Itoa(cluster.StorageGB),
-				CPU:           strconv.Itoa(cluster.CPU),
-				MEMORY:        strconv.Itoa(cluster.MemoryGB),
-				IS_HA:         strconv.FormatBool(cluster.HighAvailability),
-				STATE:         cluster.State,
-			},
+		bridgeInstances = append(bridgeInstances, instanceFromDetail(cluster))
+		// Read replicas are offered as instances of their own, pointing
+		// back at their primary
+		for _, replica := range cluster.Replicas {
+			replicaSvc := instanceFromDetail(replica)
+			replicaSvc.InstanceInfo[REPLICA_OF] = cluster.ID
+			bridgeInstances = append(bridgeInstances, replicaSvc)
 		}
-		bridgeInstances = append(bridgeInstances, clusterSvc)
 	}
 
 	dbaasredhatcomv1alpha1.Status.Instances = bridgeInstances
 
 	return nil
 }
+
+// instanceFromDetail describes a cluster as an inventory instance
+func instanceFromDetail(cluster bridgeapi.ClusterDetail) dbaasv1alpha1.Instance {
+	return dbaasv1alpha1.Instance{
+		InstanceID: cluster.ID,
+		Name:       cluster.Name,
+		InstanceInfo: map[string]string{
+			TEAM_ID:       cluster.TeamID,
+			PROVIDER_ID:   cluster.ProviderID,
+			REGION_ID:     cluster.RegionID,
+			CREATED_AT:    cluster.Created.String(),
+			UPDATED_AT:    cluster.Updated.String(),
+			MAJOR_VERSION: strconv.Itoa(cluster.PGMajorVersion),
+			STORAGE:       strconv.Itoa(cluster.StorageGB),
+			CPU:           strconv.Itoa(cluster.CPU),
+			MEMORY:        strconv.Itoa(cluster.MemoryGB),
+			IS_HA:         strconv.FormatBool(cluster.HighAvailability),
+			STATE:         cluster.State,
+			HOST:          cluster.Host,
+		},
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	dbaasredhatcomv1alpha1 "github.com/CrunchyData/crunchy-bridge-operator/apis/dbaas.redhat.com/v1alpha1"
 	dbaasredhatcomcontrollers "github.com/CrunchyData/crunchy-bridge-operator/controllers/dbaas.redhat.com"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgepoll"
 )
 
 func init() {
@@ -26,7 +27,7 @@ func init() {
 	dbaasInit = enableDBaaSExtension
 }
 
-func enableDBaaSExtension(mgrOpts manager.Options, crunchybridgeAPIURL string) manager.Manager {
+func enableDBaaSExtension(mgrOpts manager.Options, crunchybridgeAPIURL string, pollers *bridgepoll.Registry) manager.Manager {
 	mgrOpts.NewCache = cache.BuilderWithOptions(cache.Options{
 		SelectorsByObject: cache.SelectorsByObject{
 			&corev1.Secret{}: {
@@ -85,6 +86,7 @@ func enableDBaaSExtension(mgrOpts manager.Options, crunchybridgeAPIURL string) m
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
 		APIBaseURL: crunchybridgeAPIURL,
+		Pollers:    pollers,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CrunchyBridgeInstance")
 		os.Exit(1)
@@ -8,6 +8,7 @@ require (
 	github.com/jpillora/backoff v1.0.0
 	github.com/onsi/ginkgo v1.16.5
 	github.com/onsi/gomega v1.17.0
+	github.com/prometheus/client_golang v1.11.0
 	go.uber.org/zap v1.19.1
 	k8s.io/api v0.23.5
 	k8s.io/apiextensions-apiserver v0.23.5
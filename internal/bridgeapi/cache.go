@@ -16,35 +16,48 @@ limitations under the License.
 package bridgeapi
 
 import (
+	"fmt"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 )
 
+// sessionIdleTimeout is how long a session is kept after its last client
+// is released, so that short-lived clients such as those created per
+// reconcile don't log in and out repeatedly
+const sessionIdleTimeout = 5 * time.Minute
+
 // Package global cache of loginManager sessions
 var sessionCache managerCache
 
 func init() {
 	sessionCache = managerCache{
-		store: map[string]slot{},
+		store:   map[string]slot{},
+		retired: map[*loginManager]int{},
 	}
 }
 
 type slot struct {
 	lm    *loginManager
-	count int // Maintain use count to purge unused sessions
+	count int         // Maintain use count to purge unused sessions
+	idle  *time.Timer // Pending close while count is zero
 }
 
 type managerCache struct {
 	sync.RWMutex
 	store map[string]slot
+	// retired holds use counts of sessions displaced from store by
+	// credential rotation, closed once their last client is released
+	retired map[*loginManager]int
 }
 
 func (mc *managerCache) GetSession(authURL *url.URL, cp CredentialProvider, logger logr.Logger) (*loginManager, error) {
 	cred, err := cp.ProvideCredential()
 	if err != nil {
-		return nil, err
+		// Callers may retry once the credential source becomes available
+		return nil, fmt.Errorf("%w: %s", ErrorNoCreds, err)
 	}
 	label := authURL.String() + cred.Key + cred.Secret
 
@@ -55,6 +68,10 @@ func (mc *managerCache) GetSession(authURL *url.URL, cp CredentialProvider, logg
 	if node, ok := mc.store[label]; ok {
 		node.count = node.count + 1
 		lm = node.lm
+		if node.idle != nil {
+			node.idle.Stop()
+			node.idle = nil
+		}
 
 		// The following is a guess at a potential issue resolution, the
 		// issue is not, to date, reproducible in a useful-to-verify way.
@@ -94,23 +111,175 @@ func (mc *managerCache) GetSession(authURL *url.URL, cp CredentialProvider, logg
 	return lm, nil
 }
 
+// Len returns the number of sessions held, in use or not
+func (mc *managerCache) Len() int {
+	mc.RLock()
+	defer mc.RUnlock()
+	return len(mc.store) + len(mc.retired)
+}
+
+// States returns the number of sessions held in each login state
+func (mc *managerCache) States() map[LoginState]int {
+	mc.RLock()
+	defer mc.RUnlock()
+
+	states := map[LoginState]int{}
+	for _, node := range mc.store {
+		states[node.lm.State()]++
+	}
+	for lm := range mc.retired {
+		states[lm.State()]++
+	}
+	return states
+}
+
+// Release gives up one use of lm. A session no longer in use is closed
+// once it has been idle for sessionIdleTimeout.
 func (mc *managerCache) Release(lm *loginManager) {
 	mc.Lock()
 	defer mc.Unlock()
 
-	if node, ok := mc.store[lm.label]; ok && node.count > 0 {
-		node.count = node.count - 1
-		mc.store[lm.label] = node
+	if count, ok := mc.retired[lm]; ok {
+		// Retired sessions are never handed out again, close right away
+		if count <= 1 {
+			delete(mc.retired, lm)
+			go lm.Close()
+		} else {
+			mc.retired[lm] = count - 1
+		}
+		return
 	}
 
-	// It seems like this could be done on the previous test, but would miss
-	// cases where the ref count went to 0 before an unused node reached inactive
+	// A session retired by rotation may share its label with a newer one
+	node, ok := mc.store[lm.label]
+	if !ok || node.lm != lm || node.count <= 0 {
+		return
+	}
+	node.count = node.count - 1
+	if node.count == 0 {
+		node.idle = time.AfterFunc(sessionIdleTimeout, func() { mc.closeIdle(lm) })
+	}
+	mc.store[lm.label] = node
+}
+
+// closeIdle removes and closes lm if it is still unused
+func (mc *managerCache) closeIdle(lm *loginManager) {
+	mc.Lock()
+	node, ok := mc.store[lm.label]
+	if !ok || node.lm != lm || node.count > 0 {
+		mc.Unlock()
+		return
+	}
+	delete(mc.store, lm.label)
+	mc.Unlock()
+
+	lm.Close()
+}
+
+// CloseSessions closes every cached session, stopping its timers and
+// revoking its token, as at process shutdown. Clients created before the
+// call can no longer be used.
+func CloseSessions() {
+	sessionCache.CloseAll()
+}
+
+// CloseAll implements CloseSessions for the cache
+func (mc *managerCache) CloseAll() {
+	var sessions []*loginManager
+
+	mc.Lock()
 	for lbl, node := range mc.store {
-		if node.count <= 0 && node.lm.State() == LoginInactive {
-			// At this point, the login is both inactive and zero, so the
-			// manager would need a whole new login anyway, good time to
-			// purge the record and save the resources
-			delete(mc.store, lbl)
+		if node.idle != nil {
+			node.idle.Stop()
 		}
+		sessions = append(sessions, node.lm)
+		delete(mc.store, lbl)
+	}
+	for lm := range mc.retired {
+		sessions = append(sessions, lm)
+		delete(mc.retired, lm)
+	}
+	mc.Unlock()
+
+	var wg sync.WaitGroup
+	for _, lm := range sessions {
+		wg.Add(1)
+		go func(lm *loginManager) {
+			defer wg.Done()
+			lm.Close()
+		}(lm)
+	}
+	wg.Wait()
+}
+
+// RefreshSessions logs in again right away for every cached session whose
+// credential provider satisfies match and now provides credentials other
+// than the ones the session was established with. Superseded tokens are
+// revoked. It returns the number of sessions refreshed.
+func RefreshSessions(match func(CredentialProvider) bool) int {
+	return sessionCache.Refresh(match)
+}
+
+// Refresh implements RefreshSessions for the cache. Rotated sessions move to
+// the slot for their new credentials, or are retired from the cache if that
+// slot is taken, remaining in use by the clients which already hold them.
+func (mc *managerCache) Refresh(match func(CredentialProvider) bool) int {
+	candidates := map[*loginManager]CredentialProvider{}
+	mc.RLock()
+	for _, node := range mc.store {
+		if match(node.lm.loginSource) {
+			candidates[node.lm] = node.lm.loginSource
+		}
+	}
+	mc.RUnlock()
+
+	// Providers may call out for credentials, ask them outside the lock
+	labels := map[*loginManager]string{}
+	for lm, cp := range candidates {
+		cred, err := cp.ProvideCredential()
+		if err != nil {
+			lm.log.Error(err, "error retrieving credentials for refresh")
+			continue
+		}
+		labels[lm] = lm.authTarget.String() + cred.Key + cred.Secret
+	}
+
+	var rotated, closed []*loginManager
+
+	mc.Lock()
+	for lm, newLabel := range labels {
+		lbl := lm.label
+		node, ok := mc.store[lbl]
+		if !ok || node.lm != lm || newLabel == lbl {
+			// Released, or moved by a concurrent refresh, in the meantime
+			continue
+		}
+
+		delete(mc.store, lbl)
+		lm.label = newLabel
+		if _, taken := mc.store[newLabel]; !taken {
+			mc.store[newLabel] = node
+		} else {
+			if node.idle != nil {
+				node.idle.Stop()
+			}
+			if node.count == 0 {
+				closed = append(closed, lm)
+				continue
+			}
+			mc.retired[lm] = node.count
+		}
+		rotated = append(rotated, lm)
+	}
+	mc.Unlock()
+
+	// Talk to the API outside the lock, token requests may be slow
+	for _, lm := range closed {
+		lm.Close()
+	}
+	for _, lm := range rotated {
+		lm.log.Info("credentials changed, logging in again")
+		lm.rotate()
 	}
+	return len(rotated)
 }
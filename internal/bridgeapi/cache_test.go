@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+func TestCredentialRotation(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("rotation", "first")
+	ctx := context.Background()
+
+	cp := &swapCred{cred: bridgeapi.LoginCred{Key: "rotation", Secret: "first"}}
+	client, err := bridgeapi.NewClient(srv.APIURL(), cp)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID before rotation: %v", err)
+	}
+
+	srv.SetAccountSecret("rotation", "second")
+	cp.set(bridgeapi.LoginCred{Key: "rotation", Secret: "second"}, nil)
+	match := func(p bridgeapi.CredentialProvider) bool { return p == cp }
+	if n := bridgeapi.RefreshSessions(match); n != 1 {
+		t.Fatalf("RefreshSessions = %d; want 1", n)
+	}
+	if n := srv.ActiveTokens("rotation"); n != 1 {
+		t.Fatalf("ActiveTokens after rotation = %d; want 1, the old token revoked", n)
+	}
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID after rotation: %v", err)
+	}
+
+	// Unchanged credentials leave the session alone
+	if n := bridgeapi.RefreshSessions(match); n != 0 {
+		t.Fatalf("RefreshSessions without change = %d; want 0", n)
+	}
+}
+
+func TestRefreshSlowProvider(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("slow", "cbkey_slow")
+	srv.AddAccount("other", "cbkey_other")
+
+	cp := &swapCred{cred: bridgeapi.LoginCred{Key: "slow", Secret: "cbkey_slow"}}
+	client, err := bridgeapi.NewClient(srv.APIURL(), cp)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	hold := make(chan struct{})
+	setHold := func(h chan struct{}) {
+		cp.mu.Lock()
+		defer cp.mu.Unlock()
+		cp.hold = h
+	}
+	setHold(hold)
+	refreshed := make(chan int, 1)
+	go func() {
+		refreshed <- bridgeapi.RefreshSessions(func(p bridgeapi.CredentialProvider) bool { return p == cp })
+	}()
+	<-hold
+
+	// Other sessions are handed out while the provider is being asked
+	created := make(chan struct{})
+	go func() {
+		other := newTestClient(t, srv, "other", "cbkey_other")
+		other.Close()
+		close(created)
+	}()
+	var blocked bool
+	select {
+	case <-created:
+	case <-time.After(5 * time.Second):
+		blocked = true
+	}
+
+	setHold(nil)
+	hold <- struct{}{}
+	if blocked {
+		t.Fatal("NewClient blocked by a credential provider called from RefreshSessions")
+	}
+	if n := <-refreshed; n != 0 {
+		t.Errorf("RefreshSessions without change = %d; want 0", n)
+	}
+}
@@ -17,12 +17,15 @@ package bridgeapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	// "github.com/google/uuid"
@@ -32,13 +35,23 @@ var (
 	routeAccount     string = "/account"
 	routeClusters    string = "/clusters"
 	routeDefaultRole string = "/clusters/%s/roles/postgres"
+	routeRoles       string = "/clusters/%s/roles"
+	routeRole        string = "/clusters/%s/roles/%s"
+	routeProviders   string = "/providers"
+	routeReplicas    string = "/clusters/%s/replicas"
+	routeReplica     string = "/clusters/%s/replicas/%s"
 	routeTeams       string = "/teams"
+	routeUpgrade     string = "/clusters/%s/upgrade"
 )
 
 var (
 // BridgeOperatorNS = uuid.MustParse("b208adb0-ca76-40f7-ab28-8a505730bd25")
 )
 
+// DefaultRequestTimeout bounds each API request unless overridden through
+// SetRequestTimeout
+const DefaultRequestTimeout = 30 * time.Second
+
 type ClientOption func(*Client)
 
 type Client struct {
@@ -48,6 +61,9 @@ type Client struct {
 	client     *http.Client
 	session    *loginManager
 	version    string
+	timeout    time.Duration
+	retry      RetryPolicy
+	closeOnce  sync.Once
 }
 
 func NewClient(apiURL *url.URL, cp CredentialProvider, opts ...ClientOption) (*Client, error) {
@@ -61,6 +77,8 @@ func NewClient(apiURL *url.URL, cp CredentialProvider, opts ...ClientOption) (*C
 		authTarget: apiURL,
 		log:        logr.Discard(),
 		client:     &http.Client{},
+		timeout:    DefaultRequestTimeout,
+		retry:      DefaultRetryPolicy,
 	}
 
 	for _, opt := range opts {
@@ -98,6 +116,14 @@ func SetHTTPClient(hc *http.Client) ClientOption {
 	}
 }
 
+// SetRequestTimeout sets the default time limit for each API request, a
+// zero or negative value leaves requests bounded only by the caller's context
+func SetRequestTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
 // SetVersion sets the operator version in the client for self-identification
 func SetVersion(ver string) ClientOption {
 	return func(c *Client) {
@@ -105,6 +131,19 @@ func SetVersion(ver string) ClientOption {
 	}
 }
 
+// Close releases the client's use of its login session, which is shared
+// with other clients using the same credentials. Once unused for a while,
+// the session stops refreshing and its token is revoked. The client must
+// not be used after Close.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		if c.session != nil {
+			sessionCache.Release(c.session)
+		}
+	})
+	return nil
+}
+
 func (c *Client) precheck() error {
 	// Attempt to refresh login state if inactive (and not bad creds)
 	if c.session != nil {
@@ -116,6 +155,15 @@ func (c *Client) precheck() error {
 	return c.GetLoginState().toError()
 }
 
+// withTimeout bounds ctx by the default request timeout, an earlier
+// deadline already set on ctx still applies
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
 func (c *Client) GetLoginState() LoginState {
 	return c.session.State()
 }
@@ -135,21 +183,27 @@ func (c *Client) setCommonHeaders(req *http.Request) {
 	c.setUserAgent(req)
 }
 
-func (c *Client) CreateCluster(cr CreateRequest) error {
+// CreateCluster requests a new cluster, returning the detail of the cluster
+// being provisioned
+func (c *Client) CreateCluster(ctx context.Context, cr CreateRequest) (ClusterDetail, error) {
 	if err := c.precheck(); err != nil {
-		return err
+		return ClusterDetail{}, err
 	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	// TODO: Identify personal team id if not provided in request
 
 	reqPayload, err := json.Marshal(cr)
 	if err != nil {
 		c.log.Error(err, "during encoding cluster request")
-		return err
+		return ClusterDetail{}, err
 	}
-	req, err := http.NewRequest(http.MethodPost, c.apiTarget.String()+routeClusters, bytes.NewReader(reqPayload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiTarget.String()+routeClusters, bytes.NewReader(reqPayload))
 	if err != nil {
 		c.log.Error(err, "during create cluster request")
-		return err
+		return ClusterDetail{}, err
 	}
 	c.setCommonHeaders(req)
 	// Disable Idempotency Key setting - futher evaluation is ongoing w.r.t.
@@ -164,38 +218,30 @@ func (c *Client) CreateCluster(cr CreateRequest) error {
 	// req.Header.Set("Idempotency-Key", idemKey.String())
 	//
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during create cluster")
-		return err
+		return ClusterDetail{}, err
 	}
 	defer resp.Body.Close()
 
-	// APIMessage is the default response format when the API function doesn't
-	// return the documented response type
-	var mesg APIMessage
-	if resp.StatusCode != http.StatusCreated {
-		err = json.NewDecoder(resp.Body).Decode(&mesg)
-		if err != nil {
-			c.log.Error(err, "error unmarshaling API error response")
-			// Move forward with errors based on http code
-			mesg.Message = "unable to retrieve further error details"
-		}
-	}
-
 	switch resp.StatusCode {
-	case http.StatusCreated:
-		return nil
-	case http.StatusBadRequest:
-		c.log.Info("Create API bad request message", "message", mesg.Message, "request_id", mesg.RequestID)
-		return fmt.Errorf("%w: %s", ErrorBadRequest, mesg.Message)
-	case http.StatusConflict:
-		c.log.Info("Create API conflict message", "message", mesg.Message, "request_id", mesg.RequestID)
-		return fmt.Errorf("%w: %s", ErrorConflict, mesg.Message)
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
 	default:
-		c.log.Info("unrecognized return status from create call", "statusCode", resp.StatusCode)
-		return nil
+		apiErr := newAPIError(resp, "create cluster")
+		c.log.Info("unexpected status code from API (create cluster)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterDetail{}, apiErr
+	}
+
+	var detail ClusterDetail
+	err = json.NewDecoder(resp.Body).Decode(&detail)
+	if err != nil {
+		c.log.Error(err, "error unmarshaling response body (create cluster)")
+		return ClusterDetail{}, err
 	}
+
+	return detail, nil
 }
 
 // ClusterByName returns the cluster detail for the named cluster
@@ -204,38 +250,41 @@ func (c *Client) CreateCluster(cr CreateRequest) error {
 // cluster endpoint. This pivot is required as the cluster list does not
 // include the state field
 //
-// Returns a zero-value ClusterDetail and nil error when not found
-func (c *Client) ClusterByName(name string) (ClusterDetail, error) {
+// Returns an error wrapping ErrorNotFound when no cluster has the name
+func (c *Client) ClusterByName(ctx context.Context, name string) (ClusterDetail, error) {
 	if err := c.precheck(); err != nil {
 		return ClusterDetail{}, err
 	}
 
-	clustList, err := c.ListAllClusters()
+	clustList, err := c.ListAllClusters(ctx)
 	if err != nil {
 		return ClusterDetail{}, err
 	}
 
 	for _, cluster := range clustList.Clusters {
 		if cluster.Name == name {
-			return c.ClusterDetail(cluster.ID)
+			return c.ClusterDetail(ctx, cluster.ID)
 		}
 	}
-	return ClusterDetail{}, nil
+	return ClusterDetail{}, fmt.Errorf("cluster %q: %w", name, ErrorNotFound)
 }
 
-func (c *Client) ListClusters() (ClusterList, error) {
+func (c *Client) ListClusters(ctx context.Context) (ClusterList, error) {
 	if err := c.precheck(); err != nil {
 		return ClusterList{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, c.apiTarget.String()+routeClusters, nil)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiTarget.String()+routeClusters, nil)
 	if err != nil {
 		c.log.Error(err, "during list personal clusters request prep")
 		return ClusterList{}, err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during personal cluster list request")
 		return ClusterList{}, err
@@ -243,8 +292,10 @@ func (c *Client) ListClusters() (ClusterList, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.log.Info("unexpected status code from API (cluster list)", "statusCode", resp.StatusCode)
-		return ClusterList{}, errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "cluster list")
+		c.log.Info("unexpected status code from API (cluster list)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterList{}, apiErr
 	}
 
 	var myList ClusterList
@@ -256,21 +307,24 @@ func (c *Client) ListClusters() (ClusterList, error) {
 	return myList, nil
 }
 
-func (c *Client) ListTeamClusters(teamID string) (ClusterList, error) {
+func (c *Client) ListTeamClusters(ctx context.Context, teamID string) (ClusterList, error) {
 	if err := c.precheck(); err != nil {
 		return ClusterList{}, err
 	}
 
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	reqURL := fmt.Sprintf("%s%s?team_id=%s", c.apiTarget, routeClusters, teamID)
 
-	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
 		c.log.Error(err, "during list team clusters request prep")
 		return ClusterList{}, err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during team cluster list request")
 		return ClusterList{}, err
@@ -278,8 +332,10 @@ func (c *Client) ListTeamClusters(teamID string) (ClusterList, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.log.Info("unexpected status code from API (team cluster list)", "statusCode", resp.StatusCode)
-		return ClusterList{}, errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "team cluster list")
+		c.log.Info("unexpected status code from API (team cluster list)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterList{}, apiErr
 	}
 
 	var teamList ClusterList
@@ -293,19 +349,23 @@ func (c *Client) ListTeamClusters(teamID string) (ClusterList, error) {
 
 // ListAllClusters returns all clusters visible to the user, including both
 // personal clusters and team visibility
-func (c *Client) ListAllClusters() (ClusterList, error) {
+func (c *Client) ListAllClusters(ctx context.Context) (ClusterList, error) {
 	if err := c.precheck(); err != nil {
 		return ClusterList{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, c.apiTarget.String()+routeTeams, nil)
+	// Team clusters are listed under their own request timeouts
+	reqCtx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, c.apiTarget.String()+routeTeams, nil)
 	if err != nil {
 		c.log.Error(err, "during list teams prep")
 		return ClusterList{}, err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during list teams")
 		return ClusterList{}, err
@@ -313,8 +373,10 @@ func (c *Client) ListAllClusters() (ClusterList, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.log.Info("unexpected status code from API (team list)", "statusCode", resp.StatusCode)
-		return ClusterList{}, errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "team list")
+		c.log.Info("unexpected status code from API (team list)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterList{}, apiErr
 	}
 
 	var teamList struct {
@@ -332,7 +394,7 @@ func (c *Client) ListAllClusters() (ClusterList, error) {
 		Clusters: []ClusterDetail{},
 	}
 	for _, team := range teamList.Teams {
-		toAdd, err := c.ListTeamClusters(team.ID)
+		toAdd, err := c.ListTeamClusters(ctx, team.ID)
 		if err != nil {
 			return ClusterList{}, err
 		}
@@ -351,21 +413,24 @@ func (c *Client) ListAllClusters() (ClusterList, error) {
 
 // DefaultConnRole returns the default connection role for the cluster
 // identified by id
-func (c *Client) DefaultConnRole(id string) (ConnectionRole, error) {
+func (c *Client) DefaultConnRole(ctx context.Context, id string) (ConnectionRole, error) {
 	if err := c.precheck(); err != nil {
 		return ConnectionRole{}, err
 	}
 
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	route := fmt.Sprintf(c.apiTarget.String()+routeDefaultRole, id)
 
-	req, err := http.NewRequest(http.MethodGet, route, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, route, nil)
 	if err != nil {
 		c.log.Error(err, "during cluster role request prep")
 		return ConnectionRole{}, err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during cluster role request")
 		return ConnectionRole{}, err
@@ -373,8 +438,10 @@ func (c *Client) DefaultConnRole(id string) (ConnectionRole, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.log.Info("unexpected status code from API(cluster role)", "statusCode", resp.StatusCode)
-		return ConnectionRole{}, errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "cluster role")
+		c.log.Info("unexpected status code from API (cluster role)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ConnectionRole{}, apiErr
 	}
 
 	var role ConnectionRole
@@ -387,21 +454,157 @@ func (c *Client) DefaultConnRole(id string) (ConnectionRole, error) {
 	return role, nil
 }
 
-func (c *Client) ClusterDetail(id string) (ClusterDetail, error) {
+// CreateRole creates a new database role on the cluster identified by
+// clusterID. The API assigns a role name when name is blank.
+func (c *Client) CreateRole(ctx context.Context, clusterID, name string) (ConnectionRole, error) {
+	if err := c.precheck(); err != nil {
+		return ConnectionRole{}, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqPayload, err := json.Marshal(RoleRequest{Name: name})
+	if err != nil {
+		c.log.Error(err, "during encoding role request")
+		return ConnectionRole{}, err
+	}
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeRoles, clusterID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, route, bytes.NewReader(reqPayload))
+	if err != nil {
+		c.log.Error(err, "during create role request prep")
+		return ConnectionRole{}, err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during create role request")
+		return ConnectionRole{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		apiErr := newAPIError(resp, "create role")
+		c.log.Info("unexpected status code from API (create role)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ConnectionRole{}, apiErr
+	}
+
+	var role ConnectionRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
+	if err != nil {
+		c.log.Error(err, "error unmarshaling response body (create role)")
+		return ConnectionRole{}, err
+	}
+
+	return role, nil
+}
+
+// Role returns the named database role for the cluster identified by
+// clusterID
+func (c *Client) Role(ctx context.Context, clusterID, name string) (ConnectionRole, error) {
+	if err := c.precheck(); err != nil {
+		return ConnectionRole{}, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeRole, clusterID, name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, route, nil)
+	if err != nil {
+		c.log.Error(err, "during role request prep")
+		return ConnectionRole{}, err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during role request")
+		return ConnectionRole{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp, "role")
+		c.log.Info("unexpected status code from API (role)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ConnectionRole{}, apiErr
+	}
+
+	var role ConnectionRole
+	err = json.NewDecoder(resp.Body).Decode(&role)
+	if err != nil {
+		c.log.Error(err, "error unmarshaling response body (role)")
+		return ConnectionRole{}, err
+	}
+
+	return role, nil
+}
+
+// DeleteRole drops the named database role from the cluster identified by
+// clusterID
+func (c *Client) DeleteRole(ctx context.Context, clusterID, name string) error {
+	if err := c.precheck(); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeRole, clusterID, name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, route, nil)
+	if err != nil {
+		c.log.Error(err, "during role delete request prep")
+		return err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during role delete request")
+		return err
+	}
+	defer resp.Body.Close()
+
+	// A missing role (or cluster) leaves nothing to remove
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		apiErr := newAPIError(resp, "role delete")
+		c.log.Info("unexpected status code from API (role delete)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return apiErr
+	}
+
+	return nil
+}
+
+func (c *Client) ClusterDetail(ctx context.Context, id string) (ClusterDetail, error) {
 	if err := c.precheck(); err != nil {
 		return ClusterDetail{}, err
 	}
 
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	route := fmt.Sprintf("%s%s/%s", c.apiTarget, routeClusters, id)
 
-	req, err := http.NewRequest(http.MethodGet, route, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, route, nil)
 	if err != nil {
 		c.log.Error(err, "during cluster detail request")
 		return ClusterDetail{}, err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during cluster detail request prep")
 		return ClusterDetail{}, err
@@ -409,8 +612,10 @@ func (c *Client) ClusterDetail(id string) (ClusterDetail, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.log.Info("unexpected status code from API(cluster detail)", "statusCode", resp.StatusCode)
-		return ClusterDetail{}, errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "cluster detail")
+		c.log.Info("unexpected status code from API (cluster detail)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterDetail{}, apiErr
 	}
 
 	var detail ClusterDetail
@@ -423,48 +628,237 @@ func (c *Client) ClusterDetail(id string) (ClusterDetail, error) {
 	return detail, nil
 }
 
-func (c *Client) DeleteCluster(id string) error {
+// UpdateCluster requests a change to the plan, storage, high availability or
+// major version of the cluster identified by id. Bridge carries out the
+// change asynchronously, so completion needs to be confirmed through
+// ClusterDetail
+func (c *Client) UpdateCluster(ctx context.Context, id string, ur UpdateRequest) error {
+	if err := c.precheck(); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqPayload, err := json.Marshal(ur)
+	if err != nil {
+		c.log.Error(err, "during encoding cluster update request")
+		return err
+	}
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeUpgrade, id)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, route, bytes.NewReader(reqPayload))
+	if err != nil {
+		c.log.Error(err, "during cluster update request prep")
+		return err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during cluster update request")
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return nil
+	}
+
+	apiErr := newAPIError(resp, "cluster update")
+	c.log.Info("unexpected status code from API (cluster update)", "statusCode", resp.StatusCode,
+		"message", apiErr.Message, "request_id", apiErr.RequestID)
+	return apiErr
+}
+
+func (c *Client) DeleteCluster(ctx context.Context, id string) error {
 	if err := c.precheck(); err != nil {
 		return err
 	}
 
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	route := fmt.Sprintf("%s%s/%s", c.apiTarget, routeClusters, id)
 
-	req, err := http.NewRequest(http.MethodDelete, route, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, route, nil)
 	if err != nil {
 		c.log.Error(err, "during cluster delete request")
 		return err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during cluster delete request prep")
 		return err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		apiErr := newAPIError(resp, "cluster delete")
+		c.log.Info("unexpected status code from API (cluster delete)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return apiErr
+	}
+
+	return nil
+}
+
+// CreateReplica requests a read replica of the cluster identified by
+// clusterID, returning the detail of the replica being provisioned
+func (c *Client) CreateReplica(ctx context.Context, clusterID string, rr ReplicaRequest) (ClusterDetail, error) {
+	if err := c.precheck(); err != nil {
+		return ClusterDetail{}, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	reqPayload, err := json.Marshal(rr)
+	if err != nil {
+		c.log.Error(err, "during encoding replica request")
+		return ClusterDetail{}, err
+	}
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeReplicas, clusterID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, route, bytes.NewReader(reqPayload))
+	if err != nil {
+		c.log.Error(err, "during create replica request prep")
+		return ClusterDetail{}, err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during create replica request")
+		return ClusterDetail{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+	default:
+		apiErr := newAPIError(resp, "create replica")
+		c.log.Info("unexpected status code from API (create replica)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterDetail{}, apiErr
+	}
+
+	var detail ClusterDetail
+	err = json.NewDecoder(resp.Body).Decode(&detail)
+	if err != nil {
+		c.log.Error(err, "error unmarshaling response body (create replica)")
+		return ClusterDetail{}, err
+	}
+
+	return detail, nil
+}
+
+// ListReplicas returns the read replicas of the cluster identified by
+// clusterID
+func (c *Client) ListReplicas(ctx context.Context, clusterID string) (ClusterList, error) {
+	if err := c.precheck(); err != nil {
+		return ClusterList{}, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeReplicas, clusterID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, route, nil)
+	if err != nil {
+		c.log.Error(err, "during list replicas request prep")
+		return ClusterList{}, err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during list replicas request")
+		return ClusterList{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp, "replica list")
+		c.log.Info("unexpected status code from API (replica list)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ClusterList{}, apiErr
+	}
+
+	var replicas ClusterList
+	err = json.NewDecoder(resp.Body).Decode(&replicas)
+	if err != nil {
+		c.log.Error(err, "error unmarshaling response body (replica list)")
+		return ClusterList{}, err
+	}
+
+	return replicas, nil
+}
+
+// DeleteReplica removes the read replica identified by replicaID from the
+// cluster identified by clusterID
+func (c *Client) DeleteReplica(ctx context.Context, clusterID, replicaID string) error {
+	if err := c.precheck(); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	route := fmt.Sprintf(c.apiTarget.String()+routeReplica, clusterID, replicaID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, route, nil)
+	if err != nil {
+		c.log.Error(err, "during replica delete request prep")
+		return err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during replica delete request")
+		return err
+	}
+	defer resp.Body.Close()
+
+	// A missing replica leaves nothing to remove
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		c.log.Info("unexpected status code from API(cluster delete)", "statusCode", resp.StatusCode)
-		return errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "replica delete")
+		c.log.Info("unexpected status code from API (replica delete)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return apiErr
 	}
 
 	return nil
 }
 
 // DefaultTeamID returns the team id for creation requests
-func (c *Client) DefaultTeamID() (string, error) {
+func (c *Client) DefaultTeamID(ctx context.Context) (string, error) {
 	if err := c.precheck(); err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, c.apiTarget.String()+routeAccount, nil)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiTarget.String()+routeAccount, nil)
 	if err != nil {
 		c.log.Error(err, "during fetch account prep")
 		return "", err
 	}
 	c.setCommonHeaders(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		c.log.Error(err, "during fetch account")
 		return "", err
@@ -472,8 +866,10 @@ func (c *Client) DefaultTeamID() (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		c.log.Info("unexpected status code from API (account info)", "statusCode", resp.StatusCode)
-		return "", errors.New("unexpected response status from API")
+		apiErr := newAPIError(resp, "account info")
+		c.log.Info("unexpected status code from API (account info)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return "", apiErr
 	}
 
 	var account Account
@@ -490,3 +886,43 @@ func (c *Client) DefaultTeamID() (string, error) {
 		return account.DefaultTeamID, nil
 	}
 }
+
+// ListProviders returns the catalog of cloud providers along with the
+// regions and plans each offers
+func (c *Client) ListProviders(ctx context.Context) (ProviderList, error) {
+	if err := c.precheck(); err != nil {
+		return ProviderList{}, err
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiTarget.String()+routeProviders, nil)
+	if err != nil {
+		c.log.Error(err, "during list providers prep")
+		return ProviderList{}, err
+	}
+	c.setCommonHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		c.log.Error(err, "during list providers")
+		return ProviderList{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp, "provider list")
+		c.log.Info("unexpected status code from API (provider list)", "statusCode", resp.StatusCode,
+			"message", apiErr.Message, "request_id", apiErr.RequestID)
+		return ProviderList{}, apiErr
+	}
+
+	var providers ProviderList
+	err = json.NewDecoder(resp.Body).Decode(&providers)
+	if err != nil {
+		c.log.Error(err, "error unmarshaling response body for provider list")
+		return ProviderList{}, err
+	}
+	return providers, nil
+}
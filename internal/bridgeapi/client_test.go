@@ -0,0 +1,82 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+func newTestClient(t *testing.T, srv *bridgetest.Server, key, secret string, opts ...bridgeapi.ClientOption) *bridgeapi.Client {
+	t.Helper()
+	opts = append([]bridgeapi.ClientOption{
+		bridgeapi.SetRetryPolicy(bridgeapi.RetryPolicy{
+			MaxAttempts: 3,
+			Min:         time.Millisecond,
+			Max:         10 * time.Millisecond,
+		}),
+	}, opts...)
+	c, err := bridgeapi.NewClient(srv.APIURL(), bridgeapi.LoginCred{Key: key, Secret: secret}, opts...)
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	return c
+}
+
+func TestContext(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("context", "cbkey_context")
+	client := newTestClient(t, srv, "context", "cbkey_context",
+		bridgeapi.SetRequestTimeout(100*time.Millisecond))
+	defer client.Close()
+	ctx := context.Background()
+
+	// Latency beyond the caller's deadline fails the request
+	srv.InjectFault(bridgetest.Fault{Path: "/clusters", Latency: time.Second, Times: 1})
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ListClusters(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListClusters past deadline = %v; want deadline exceeded", err)
+	}
+
+	// Without a deadline of its own, the request timeout applies
+	srv.InjectFault(bridgetest.Fault{Path: "/clusters", Latency: time.Second, Times: 1})
+	start := time.Now()
+	if _, err := client.ListClusters(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListClusters past request timeout = %v; want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("ListClusters took %s; want the 100ms request timeout", elapsed)
+	}
+
+	// Cancellation ends the wait between attempts
+	waiting := newTestClient(t, srv, "context", "cbkey_context")
+	defer waiting.Close()
+	srv.InjectFault(bridgetest.Fault{Path: "/account", Status: http.StatusServiceUnavailable, RetryAfter: "10"})
+	defer srv.ClearFaults()
+	canceled, cancel := context.WithCancel(ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := waiting.DefaultTeamID(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("DefaultTeamID canceled while waiting to retry = %v; want canceled", err)
+	}
+}
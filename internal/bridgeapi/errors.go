@@ -0,0 +1,110 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinels for classes of API response, an *APIError matches the one for
+// its status code through errors.Is
+var (
+	ErrorNotFound     = errors.New("Requested resource not found")
+	ErrorUnauthorized = errors.New("Request not authorized by API")
+	ErrorRateLimited  = errors.New("Request rate limit exceeded")
+	ErrorServerError  = errors.New("API server error")
+)
+
+// APIError describes an unsuccessful response from the Crunchy Bridge API.
+// Use errors.As to retrieve it from errors returned by Client methods
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Message is the explanation provided by the API, if any
+	Message string
+	// RequestID identifies the request to Crunchy Bridge support
+	RequestID string
+	// Operation names the client call which received the response
+	Operation string
+}
+
+// newAPIError builds an APIError from an unsuccessful response, reading
+// the APIMessage body the API returns in place of the documented type
+func newAPIError(resp *http.Response, op string) *APIError {
+	var mesg APIMessage
+	if err := json.NewDecoder(resp.Body).Decode(&mesg); err != nil {
+		// Move forward with errors based on http code
+		mesg.Message = "unable to retrieve further error details"
+	}
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    mesg.Message,
+		RequestID:  mesg.RequestID,
+		Operation:  op,
+	}
+}
+
+func (e *APIError) Error() string {
+	mesg := "unexpected response status from API"
+	if sentinel := e.Unwrap(); sentinel != nil {
+		mesg = sentinel.Error()
+	}
+	mesg = fmt.Sprintf("%s (%s: %d)", mesg, e.Operation, e.StatusCode)
+	if e.Message != "" {
+		mesg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		mesg += " [request_id " + e.RequestID + "]"
+	}
+	return mesg
+}
+
+// Unwrap returns the sentinel error matching the status code, or nil if
+// the status has no sentinel
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrorBadRequest
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrorUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrorNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrorConflict
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrorServerError
+	}
+	return nil
+}
+
+// Retryable reports whether the same request may succeed if sent again
+// later, as with rate limiting and server-side failures
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsRetryable reports whether err carries an APIError which may succeed on
+// a later attempt
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+func TestAPIErrors(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("errors", "cbkey_errors")
+	client := newTestClient(t, srv, "errors", "cbkey_errors", bridgeapi.SetRetryPolicy(bridgeapi.RetryPolicy{}))
+	defer client.Close()
+	ctx := context.Background()
+
+	_, err := client.ClusterDetail(ctx, "missing")
+	var apiErr *bridgeapi.APIError
+	if !errors.Is(err, bridgeapi.ErrorNotFound) || !errors.As(err, &apiErr) {
+		t.Fatalf("ClusterDetail of unknown cluster = %v; want APIError matching ErrorNotFound", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message == "" || apiErr.RequestID == "" || apiErr.Retryable() {
+		t.Errorf("APIError = %+v; want non-retryable 404 with message and request ID", apiErr)
+	}
+
+	_, err = client.CreateCluster(ctx, bridgeapi.CreateRequest{Name: "incomplete"})
+	if !errors.Is(err, bridgeapi.ErrorBadRequest) {
+		t.Errorf("CreateCluster without plan = %v; want ErrorBadRequest", err)
+	}
+
+	for _, tc := range []struct {
+		status    int
+		sentinel  error
+		retryable bool
+	}{
+		{http.StatusUnauthorized, bridgeapi.ErrorUnauthorized, false},
+		{http.StatusForbidden, bridgeapi.ErrorUnauthorized, false},
+		{http.StatusConflict, bridgeapi.ErrorConflict, false},
+		{http.StatusTooManyRequests, bridgeapi.ErrorRateLimited, true},
+		{http.StatusInternalServerError, bridgeapi.ErrorServerError, true},
+	} {
+		srv.InjectFault(bridgetest.Fault{Path: "/account", Status: tc.status, Times: 1})
+		_, err := client.DefaultTeamID(ctx)
+		if !errors.Is(err, tc.sentinel) {
+			t.Errorf("DefaultTeamID with %d = %v; want %v", tc.status, err, tc.sentinel)
+		}
+		if bridgeapi.IsRetryable(err) != tc.retryable {
+			t.Errorf("IsRetryable with %d = %t; want %t", tc.status, !tc.retryable, tc.retryable)
+		}
+	}
+}
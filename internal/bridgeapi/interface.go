@@ -15,6 +15,8 @@ limitations under the License.
 */
 package bridgeapi
 
+import "context"
+
 type LoginCred struct {
 	Key    string
 	Secret string
@@ -32,3 +34,41 @@ func (lc LoginCred) Zero() bool {
 type CredentialProvider interface {
 	ProvideCredential() (LoginCred, error)
 }
+
+// Interface describes the Crunchy Bridge API operations used by the
+// controllers, satisfied by *Client and by in-memory fakes in tests
+type Interface interface {
+	// Accounts and teams
+	DefaultTeamID(ctx context.Context) (string, error)
+
+	// Catalog
+	ListProviders(ctx context.Context) (ProviderList, error)
+
+	// Clusters
+	CreateCluster(ctx context.Context, cr CreateRequest) (ClusterDetail, error)
+	ClusterByName(ctx context.Context, name string) (ClusterDetail, error)
+	ClusterDetail(ctx context.Context, id string) (ClusterDetail, error)
+	ListClusters(ctx context.Context) (ClusterList, error)
+	ListTeamClusters(ctx context.Context, teamID string) (ClusterList, error)
+	ListAllClusters(ctx context.Context) (ClusterList, error)
+	UpdateCluster(ctx context.Context, id string, ur UpdateRequest) error
+	DeleteCluster(ctx context.Context, id string) error
+
+	// Replicas
+	CreateReplica(ctx context.Context, clusterID string, rr ReplicaRequest) (ClusterDetail, error)
+	ListReplicas(ctx context.Context, clusterID string) (ClusterList, error)
+	DeleteReplica(ctx context.Context, clusterID, replicaID string) error
+
+	// Roles
+	DefaultConnRole(ctx context.Context, id string) (ConnectionRole, error)
+	CreateRole(ctx context.Context, clusterID, name string) (ConnectionRole, error)
+	Role(ctx context.Context, clusterID, name string) (ConnectionRole, error)
+	DeleteRole(ctx context.Context, clusterID, name string) error
+
+	// GetLoginState reports the state of the underlying API login
+	GetLoginState() LoginState
+	// Close releases the client's login session when done with the client
+	Close() error
+}
+
+var _ Interface = (*Client)(nil)
@@ -32,6 +32,9 @@ const (
 	// refreshBuffer represents the time to attempt to refresh the login
 	// in seconds prior to expiration time
 	refreshBuffer = 15
+
+	// loginTimeout bounds the token exchange request
+	loginTimeout = 30 * time.Second
 )
 
 // TODO: move login manager from package global to client internal
@@ -41,7 +44,8 @@ type loginManager struct {
 	// Not protected by mutex, only set at init
 	authTarget  *url.URL
 	loginSource CredentialProvider
-	label       string
+	// Guarded by the managerCache lock, changed on credential rotation
+	label string
 
 	// Protected via mutex
 	sync.RWMutex
@@ -52,8 +56,10 @@ type loginManager struct {
 	expireTimer   *time.Timer
 	retryDelay    backoff.Backoff
 	curState      LoginState
+	rejectedCred  LoginCred
 	lastRefresh   time.Time
 	lastUsage     time.Time
+	closed        bool
 }
 
 func newLoginManager(
@@ -89,14 +95,31 @@ func (lm *loginManager) SetLogger(logger logr.Logger) {
 // Ping attempts to refresh login state when in a temporary non-active state
 func (lm *loginManager) Ping() {
 	lm.RLock()
-	state := lm.curState
+	state, closed := lm.curState, lm.closed
 	lm.RUnlock()
 
-	if state == LoginFailed || state == LoginInactive || state == LoginUnstarted {
+	if closed {
+		return
+	} else if state == LoginFailed || state == LoginInactive || state == LoginUnstarted {
+		lm.login()
+	} else if state == LoginInvalidCreds && lm.credsReplaced() {
 		lm.login()
 	}
 }
 
+// credsReplaced reports whether the login source now provides credentials
+// other than the ones last rejected by the API
+func (lm *loginManager) credsReplaced() bool {
+	creds, err := lm.loginSource.ProvideCredential()
+	if err != nil || creds.Zero() {
+		return false
+	}
+
+	lm.RLock()
+	defer lm.RUnlock()
+	return creds != lm.rejectedCred
+}
+
 func (lm *loginManager) refreshLogin() {
 	refresh := false
 	lm.Lock()
@@ -108,9 +131,17 @@ func (lm *loginManager) refreshLogin() {
 }
 
 func (lm *loginManager) login() {
+	lm.RLock()
+	closed := lm.closed
+	lm.RUnlock()
+	if closed {
+		return
+	}
+
 	creds, err := lm.loginSource.ProvideCredential()
 	if err != nil {
 		lm.log.Error(err, "error retrieving credentials")
+		observeLogin(LoginFailed)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		return
 	}
@@ -118,6 +149,7 @@ func (lm *loginManager) login() {
 		// Fast fail login process for unset credentials, may be expected
 		// depending on "eventual consistency" usage
 		lm.log.Info("provided credentials currently blank")
+		observeLogin(LoginFailed)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		return
 	}
@@ -130,11 +162,16 @@ func (lm *loginManager) login() {
 		// no longer occurs with the newer token method - while technically correct in that an active
 		// token has been obtained (because it's now provided), it may be misleading to users.
 		lm.Lock()
+		if lm.closed {
+			lm.Unlock()
+			return
+		}
 		lm.activeToken = creds.Secret
 		lm.activeTokenID = ""
 		lm.curState = LoginActive
 		lm.retryDelay.Reset()
 		lm.Unlock()
+		observeLogin(LoginActive)
 
 		// Refresh from credential store in 10 min
 		lm.setExpiration(600)
@@ -145,16 +182,20 @@ func (lm *loginManager) login() {
 	req, err := http.NewRequest(http.MethodPost, lm.authTarget.String()+"/access-tokens", nil)
 	if err != nil {
 		lm.log.Error(err, "error creating token login request")
+		observeLogin(LoginFailed)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		lm.failLoginTemp()
 		return
 	}
 	req.SetBasicAuth(creds.Key, creds.Secret)
 
-	client := &http.Client{}
+	client := &http.Client{Timeout: loginTimeout}
+	start := time.Now()
 	resp, err := client.Do(req)
+	observeRequest(req, lm.authTarget.Path, resp, start)
 	if err != nil {
 		lm.log.Error(err, "error creating http client")
+		observeLogin(LoginFailed)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		lm.failLoginTemp()
 		return
@@ -165,13 +206,16 @@ func (lm *loginManager) login() {
 		lm.log.Error(fmt.Errorf("API returned status %d for login [%s]", resp.StatusCode, creds.Key), "login failure")
 		lm.Lock()
 		lm.curState = LoginInvalidCreds
+		lm.rejectedCred = creds
 		lm.Unlock()
+		observeLogin(LoginInvalidCreds)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		return
 	} else if resp.StatusCode != http.StatusOK {
 		lm.log.Error(
 			fmt.Errorf("API returned unexpected response %d for login [%s]", resp.StatusCode, creds.Key),
 			"unexpected login response")
+		observeLogin(LoginFailed)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		lm.failLoginTemp()
 		return
@@ -181,22 +225,77 @@ func (lm *loginManager) login() {
 	err = json.NewDecoder(resp.Body).Decode(&tr)
 	if err != nil {
 		lm.log.Error(err, "error unmarshaling token response body")
+		observeLogin(LoginFailed)
 		lm.setNextLogin(lm.retryDelay.Duration(), lm.login)
 		lm.failLoginTemp()
 		return
 	}
 
 	lm.Lock()
+	if lm.closed {
+		// Closed while the token was requested, Close had none to revoke
+		lm.Unlock()
+		lm.revokeToken(tr.Token, tr.TokenID)
+		return
+	}
 	lm.activeToken = tr.Token
 	lm.activeTokenID = tr.TokenID
 	lm.curState = LoginActive
 	lm.retryDelay.Reset()
 	lm.Unlock()
+	observeLogin(LoginActive)
 
 	lm.setExpiration(tr.ExpiresIn)
 	lm.setNextLogin(time.Duration(tr.ExpiresIn-refreshBuffer)*time.Second, lm.refreshLogin)
 }
 
+// rotate logs in with the credentials currently provided, ahead of any
+// scheduled refresh, and revokes the token obtained with the previous
+// credentials once the new login succeeds
+func (lm *loginManager) rotate() {
+	lm.RLock()
+	oldToken, oldTokenID := lm.activeToken, lm.activeTokenID
+	lm.RUnlock()
+
+	lm.login()
+
+	lm.RLock()
+	replaced := lm.curState == LoginActive && lm.activeToken != oldToken
+	lm.RUnlock()
+	if replaced && oldTokenID != "" {
+		lm.revokeToken(oldToken, oldTokenID)
+	}
+}
+
+// revokeToken invalidates an exchanged access token, API keys used directly
+// as tokens have no ID and cannot be revoked this way
+func (lm *loginManager) revokeToken(token, tokenID string) {
+	req, err := http.NewRequest(http.MethodDelete, lm.authTarget.String()+"/access-tokens/"+tokenID, nil)
+	if err != nil {
+		lm.log.Error(err, "error creating token revocation request")
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: loginTimeout}
+	start := time.Now()
+	resp, err := client.Do(req)
+	observeRequest(req, lm.authTarget.Path, resp, start)
+	if err != nil {
+		lm.log.Error(err, "error revoking access token")
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound, http.StatusUnauthorized:
+		// Revoked now or already invalid
+	default:
+		lm.log.Error(fmt.Errorf("API returned unexpected response %d for token revocation", resp.StatusCode),
+			"token revocation failure")
+	}
+}
+
 func (lm *loginManager) failLoginTemp() {
 	lm.Lock()
 	defer lm.Unlock()
@@ -213,6 +312,9 @@ func (lm *loginManager) setNextLogin(delay time.Duration, loginFunc func()) {
 	if lm.refreshTimer != nil {
 		lm.refreshTimer.Stop()
 	}
+	if lm.closed {
+		return
+	}
 	lm.refreshTimer = time.AfterFunc(delay, loginFunc)
 }
 
@@ -241,9 +343,37 @@ func (lm *loginManager) setExpiration(sec int64) {
 	if lm.expireTimer != nil {
 		lm.expireTimer.Stop()
 	}
+	if lm.closed {
+		return
+	}
 	lm.expireTimer = time.AfterFunc(time.Second*time.Duration(sec), lm.expireLogin)
 }
 
+// Close stops the refresh and expiration timers and revokes the active
+// token, leaving the session inactive for good
+func (lm *loginManager) Close() {
+	lm.Lock()
+	if lm.closed {
+		lm.Unlock()
+		return
+	}
+	lm.closed = true
+	if lm.refreshTimer != nil {
+		lm.refreshTimer.Stop()
+	}
+	if lm.expireTimer != nil {
+		lm.expireTimer.Stop()
+	}
+	token, tokenID := lm.activeToken, lm.activeTokenID
+	lm.activeToken, lm.activeTokenID = "", ""
+	lm.curState = LoginInactive
+	lm.Unlock()
+
+	if tokenID != "" {
+		lm.revokeToken(token, tokenID)
+	}
+}
+
 func (lm *loginManager) State() LoginState {
 	lm.RLock()
 	defer lm.RUnlock()
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+// swapCred is a CredentialProvider whose credentials can be replaced. With
+// hold set, callers announce themselves on it and wait to be let go.
+type swapCred struct {
+	mu   sync.Mutex
+	cred bridgeapi.LoginCred
+	err  error
+	hold chan struct{}
+}
+
+func (sc *swapCred) ProvideCredential() (bridgeapi.LoginCred, error) {
+	sc.mu.Lock()
+	hold := sc.hold
+	sc.mu.Unlock()
+	if hold != nil {
+		hold <- struct{}{}
+		<-hold
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.cred, sc.err
+}
+
+func (sc *swapCred) set(cred bridgeapi.LoginCred, err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.cred, sc.err = cred, err
+}
+
+func TestLoginRecovery(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("recovery", "secret")
+	ctx := context.Background()
+
+	cp := &swapCred{err: errors.New("secret not found")}
+	if _, err := bridgeapi.NewClient(srv.APIURL(), cp); !errors.Is(err, bridgeapi.ErrorNoCreds) {
+		t.Fatalf("NewClient without credentials = %v; want ErrorNoCreds", err)
+	}
+
+	cp.set(bridgeapi.LoginCred{Key: "recovery", Secret: "wrong"}, nil)
+	client, err := bridgeapi.NewClient(srv.APIURL(), cp)
+	if err != nil {
+		t.Fatalf("NewClient with rejected credentials: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.DefaultTeamID(ctx); !errors.Is(err, bridgeapi.ErrorInvalidCreds) {
+		t.Fatalf("DefaultTeamID with rejected credentials = %v; want ErrorInvalidCreds", err)
+	}
+
+	// Corrected credentials are picked up on the next call
+	cp.set(bridgeapi.LoginCred{Key: "recovery", Secret: "secret"}, nil)
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID after correcting credentials: %v", err)
+	}
+	if state := client.GetLoginState(); state != bridgeapi.LoginActive {
+		t.Fatalf("GetLoginState = %s; want active", state)
+	}
+}
+
+func TestSessionClose(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("closing", "secret")
+	ctx := context.Background()
+
+	client := newTestClient(t, srv, "closing", "secret")
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID: %v", err)
+	}
+
+	// Released sessions linger for reuse before being closed
+	client.Close()
+	if n := srv.ActiveTokens("closing"); n != 1 {
+		t.Fatalf("ActiveTokens after Close = %d; want 1", n)
+	}
+
+	bridgeapi.CloseSessions()
+	if n := srv.ActiveTokens("closing"); n != 0 {
+		t.Fatalf("ActiveTokens after CloseSessions = %d; want 0", n)
+	}
+
+	client = newTestClient(t, srv, "closing", "secret")
+	defer client.Close()
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID with a new session: %v", err)
+	}
+}
+
+func TestCloseDuringLogin(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("racing", "first")
+
+	cp := &swapCred{cred: bridgeapi.LoginCred{Key: "racing", Secret: "first"}}
+	client, err := bridgeapi.NewClient(srv.APIURL(), cp)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	// Rotation logs in again outside the cache lock, close the session
+	// while that login waits on the API
+	srv.SetAccountSecret("racing", "second")
+	cp.set(bridgeapi.LoginCred{Key: "racing", Secret: "second"}, nil)
+	srv.InjectFault(bridgetest.Fault{Method: http.MethodPost, Path: "/access-tokens", Latency: 200 * time.Millisecond, Times: 1})
+	refreshed := make(chan int)
+	go func() {
+		refreshed <- bridgeapi.RefreshSessions(func(p bridgeapi.CredentialProvider) bool { return p == cp })
+	}()
+	time.Sleep(50 * time.Millisecond)
+	bridgeapi.CloseSessions()
+	<-refreshed
+
+	if n := srv.ActiveTokens("racing"); n != 0 {
+		t.Fatalf("ActiveTokens after closing during login = %d; want 0", n)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "crunchybridge"
+
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_requests_total",
+		Help:      "Crunchy Bridge API requests by route, method and status code, each retry counted on its own.",
+	}, []string{"route", "method", "code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of Crunchy Bridge API requests by route, method and status code.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"route", "method", "code"})
+
+	loginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "login_attempts_total",
+		Help:      "Crunchy Bridge login attempts by the login state they resulted in.",
+	}, []string{"state"})
+
+	loginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "login_failures_total",
+		Help:      "Failed Crunchy Bridge login attempts by the login state they resulted in.",
+	}, []string{"state"})
+
+	loginSessions = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "login_sessions",
+		Help:      "Login sessions held in the session cache, including idle and retired sessions.",
+	}, func() float64 { return float64(sessionCache.Len()) })
+
+	loginStates = sessionStateCollector{prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "login_sessions_by_state"),
+		"Login sessions held in the session cache by their current login state.",
+		[]string{"state"}, nil,
+	)}
+
+	rateLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "api_rate_limit",
+		Help:      "Rate limit headers from the most recent Crunchy Bridge API response carrying them.",
+	}, []string{"header"})
+)
+
+// rateLimitHeaders are recorded by rateLimit when present on a response
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		apiRequests,
+		apiRequestDuration,
+		loginAttempts,
+		loginFailures,
+		loginSessions,
+		loginStates,
+		rateLimit,
+	)
+}
+
+// send performs a single attempt of req, recording it in the API metrics
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	observeRequest(req, c.apiTarget.Path, resp, start)
+	return resp, err
+}
+
+// observeRequest records the outcome of one HTTP request to the API, started
+// at start. A request without a response is counted with code "error".
+func observeRequest(req *http.Request, base string, resp *http.Response, start time.Time) {
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+		observeRateLimit(resp.Header)
+	}
+	route := routeLabel(strings.TrimPrefix(req.URL.Path, base))
+
+	apiRequests.WithLabelValues(route, req.Method, code).Inc()
+	apiRequestDuration.WithLabelValues(route, req.Method, code).Observe(time.Since(start).Seconds())
+}
+
+func observeRateLimit(h http.Header) {
+	for _, name := range rateLimitHeaders {
+		if val, err := strconv.ParseFloat(h.Get(name), 64); err == nil {
+			rateLimit.WithLabelValues(strings.ToLower(name)).Set(val)
+		}
+	}
+}
+
+// observeLogin records a login attempt resulting in state
+func observeLogin(state LoginState) {
+	label := stateLabel(state)
+	loginAttempts.WithLabelValues(label).Inc()
+	if state != LoginActive {
+		loginFailures.WithLabelValues(label).Inc()
+	}
+}
+
+// stateLabel returns the metric label for state
+func stateLabel(state LoginState) string {
+	return strings.ReplaceAll(state.String(), " ", "_")
+}
+
+// sessionStateCollector reports the sessions of the session cache by login
+// state as they are scraped
+type sessionStateCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c sessionStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c sessionStateCollector) Collect(ch chan<- prometheus.Metric) {
+	for state, n := range sessionCache.States() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), stateLabel(state))
+	}
+}
+
+// routeLabel turns a request path into its route template, replacing the
+// cluster, role and token identifiers found at every other path segment so
+// that the label doesn't grow with the number of clusters
+func routeLabel(path string) string {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segs); i += 2 {
+		switch segs[i-1] {
+		case "roles":
+			segs[i] = "{name}"
+		default:
+			segs[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segs, "/")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+// metricValue returns the value of the counter or gauge named name with the
+// given labels from the controller-runtime registry
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+	next:
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if want, ok := labels[lp.GetName()]; ok && want != lp.GetValue() {
+					continue next
+				}
+			}
+			if m.GetCounter() != nil {
+				return m.GetCounter().GetValue()
+			}
+			return m.GetGauge().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestMetrics(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("metrics", "secret")
+	srv.AddAccount("metrics-bad", "secret")
+	ctx := context.Background()
+
+	detailLabels := map[string]string{"route": "/clusters/{id}", "method": http.MethodGet, "code": "200"}
+	detailsBefore := metricValue(t, "crunchybridge_api_requests_total", detailLabels)
+	activeBefore := metricValue(t, "crunchybridge_login_attempts_total", map[string]string{"state": "active"})
+	invalidBefore := metricValue(t, "crunchybridge_login_failures_total", map[string]string{"state": "invalid_credentials"})
+
+	client := newTestClient(t, srv, "metrics", "secret")
+	defer client.Close()
+	id := srv.AddCluster(bridgeapi.ClusterDetail{Name: "metered", TeamID: acctID})
+
+	srv.InjectFault(bridgetest.Fault{Path: "/clusters", Times: 1, Header: http.Header{
+		"X-Ratelimit-Limit":     []string{"100"},
+		"X-Ratelimit-Remaining": []string{"42"},
+	}})
+	if _, err := client.ClusterDetail(ctx, id); err != nil {
+		t.Fatalf("ClusterDetail: %v", err)
+	}
+	if got := metricValue(t, "crunchybridge_api_requests_total", detailLabels); got != detailsBefore+1 {
+		t.Errorf("cluster detail requests = %v; want %v", got, detailsBefore+1)
+	}
+	if got := metricValue(t, "crunchybridge_api_rate_limit", map[string]string{"header": "x-ratelimit-remaining"}); got != 42 {
+		t.Errorf("rate limit remaining = %v; want 42", got)
+	}
+	if got := metricValue(t, "crunchybridge_login_attempts_total", map[string]string{"state": "active"}); got != activeBefore+1 {
+		t.Errorf("active login attempts = %v; want %v", got, activeBefore+1)
+	}
+	if got := metricValue(t, "crunchybridge_login_sessions", nil); got < 1 {
+		t.Errorf("login sessions = %v; want at least 1", got)
+	}
+	if got := metricValue(t, "crunchybridge_login_sessions_by_state", map[string]string{"state": "active"}); got < 1 {
+		t.Errorf("active login sessions = %v; want at least 1", got)
+	}
+
+	// Role names are folded into the route like cluster IDs
+	roleLabels := map[string]string{"route": "/clusters/{id}/roles/{name}", "method": http.MethodGet, "code": "404"}
+	rolesBefore := metricValue(t, "crunchybridge_api_requests_total", roleLabels)
+	if _, err := client.Role(ctx, id, "missing"); !errors.Is(err, bridgeapi.ErrorNotFound) {
+		t.Fatalf("Role = %v; want ErrorNotFound", err)
+	}
+	if got := metricValue(t, "crunchybridge_api_requests_total", roleLabels); got != rolesBefore+1 {
+		t.Errorf("role requests = %v; want %v", got, rolesBefore+1)
+	}
+
+	bad := newTestClient(t, srv, "metrics-bad", "wrong")
+	defer bad.Close()
+	if got := metricValue(t, "crunchybridge_login_failures_total", map[string]string{"state": "invalid_credentials"}); got != invalidBefore+1 {
+		t.Errorf("invalid credential login failures = %v; want %v", got, invalidBefore+1)
+	}
+}
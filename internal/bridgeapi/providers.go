@@ -0,0 +1,203 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFilePollInterval is how often a FileCredentialProvider checks
+	// its files for changes unless configured otherwise
+	DefaultFilePollInterval = 10 * time.Second
+
+	// defaultExecTimeout bounds a run of the exec credential helper
+	defaultExecTimeout = 30 * time.Second
+
+	// defaultExecCacheTTL is how long exec credentials are reused when the
+	// helper doesn't report an expiration
+	defaultExecCacheTTL = 5 * time.Minute
+)
+
+// FileCredentialProvider reads credentials from files in a directory, laid
+// out as a mounted Secret with one file per field. The files are read on
+// every request for credentials, and Start polls them so that sessions log
+// in again as soon as the content changes.
+type FileCredentialProvider struct {
+	// Dir is the directory holding the credential files
+	Dir string
+	// KeyFile names the file holding the API key
+	KeyFile string
+	// SecretFile names the file holding the API secret
+	SecretFile string
+	// PollInterval sets how often Start checks for changes, defaulting to
+	// DefaultFilePollInterval
+	PollInterval time.Duration
+}
+
+func (fp *FileCredentialProvider) ProvideCredential() (LoginCred, error) {
+	key, err := os.ReadFile(filepath.Join(fp.Dir, fp.KeyFile))
+	if err != nil {
+		return LoginCred{}, err
+	}
+	secret, err := os.ReadFile(filepath.Join(fp.Dir, fp.SecretFile))
+	if err != nil {
+		return LoginCred{}, err
+	}
+
+	return LoginCred{
+		Key:    strings.TrimSpace(string(key)),
+		Secret: strings.TrimSpace(string(secret)),
+	}, nil
+}
+
+// Start polls the credential files until ctx is done, refreshing the
+// sessions using this provider when the credentials change. It matches the
+// controller-runtime Runnable interface.
+func (fp *FileCredentialProvider) Start(ctx context.Context) error {
+	interval := fp.PollInterval
+	if interval <= 0 {
+		interval = DefaultFilePollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, _ := fp.ProvideCredential()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		cur, err := fp.ProvideCredential()
+		if err != nil || cur == last {
+			// Partially written or missing files are picked up next round
+			continue
+		}
+		last = cur
+		RefreshSessions(func(cp CredentialProvider) bool { return cp == fp })
+	}
+}
+
+// NeedLeaderElection allows every replica to follow credential changes
+func (fp *FileCredentialProvider) NeedLeaderElection() bool {
+	return false
+}
+
+// EnvCredentialProvider reads credentials from environment variables
+type EnvCredentialProvider struct {
+	// KeyVar names the variable holding the API key
+	KeyVar string
+	// SecretVar names the variable holding the API secret
+	SecretVar string
+}
+
+func (ep *EnvCredentialProvider) ProvideCredential() (LoginCred, error) {
+	key, ok := os.LookupEnv(ep.KeyVar)
+	if !ok {
+		return LoginCred{}, fmt.Errorf("environment variable %s not set", ep.KeyVar)
+	}
+	secret, ok := os.LookupEnv(ep.SecretVar)
+	if !ok {
+		return LoginCred{}, fmt.Errorf("environment variable %s not set", ep.SecretVar)
+	}
+
+	return LoginCred{Key: key, Secret: secret}, nil
+}
+
+// ExecCredential is the JSON document an exec credential helper writes to
+// stdout
+type ExecCredential struct {
+	Key    string `json:"key"`
+	Secret string `json:"secret"`
+	// ExpirationTimestamp, if set, is when the credentials stop being valid
+	// and the helper should be run again
+	ExpirationTimestamp *time.Time `json:"expiration_timestamp,omitempty"`
+}
+
+// ExecCredentialProvider runs a helper command to obtain credentials, in
+// the style of client-go credential plugins. The helper writes an
+// ExecCredential to stdout, its stderr is passed through for diagnostics.
+// Results are reused until they expire.
+type ExecCredentialProvider struct {
+	// Command is the helper to run, looked up in PATH if not a path
+	Command string
+	// Args are passed to the helper
+	Args []string
+	// Env is added to the operator's environment for the helper, as
+	// "NAME=value" pairs
+	Env []string
+	// Timeout bounds each run, defaulting to 30 seconds
+	Timeout time.Duration
+	// CacheTTL sets how long results without an expiration are reused,
+	// defaulting to 5 minutes
+	CacheTTL time.Duration
+
+	mu      sync.Mutex
+	cached  LoginCred
+	expires time.Time
+}
+
+func (xp *ExecCredentialProvider) ProvideCredential() (LoginCred, error) {
+	xp.mu.Lock()
+	defer xp.mu.Unlock()
+
+	if !xp.cached.Zero() && time.Now().Before(xp.expires) {
+		return xp.cached, nil
+	}
+
+	timeout := xp.Timeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, xp.Command, xp.Args...)
+	cmd.Env = append(os.Environ(), xp.Env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return LoginCred{}, fmt.Errorf("running credential helper %s: %w", xp.Command, err)
+	}
+
+	var ec ExecCredential
+	if err := json.Unmarshal(stdout.Bytes(), &ec); err != nil {
+		return LoginCred{}, fmt.Errorf("decoding credential helper output: %w", err)
+	}
+
+	ttl := xp.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultExecCacheTTL
+	}
+	xp.cached = LoginCred{Key: ec.Key, Secret: ec.Secret}
+	xp.expires = time.Now().Add(ttl)
+	if ec.ExpirationTimestamp != nil {
+		xp.expires = *ec.ExpirationTimestamp
+	}
+	return xp.cached, nil
+}
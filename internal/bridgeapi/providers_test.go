@@ -0,0 +1,135 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+func TestFileCredentials(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("files", "first")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	write := func(name, value string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("api_key", "files")
+	write("api_secret", "first")
+
+	fcp := &bridgeapi.FileCredentialProvider{
+		Dir:          dir,
+		KeyFile:      "api_key",
+		SecretFile:   "api_secret",
+		PollInterval: 10 * time.Millisecond,
+	}
+	go fcp.Start(ctx)
+
+	client, err := bridgeapi.NewClient(srv.APIURL(), fcp)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID: %v", err)
+	}
+
+	// A changed secret file replaces the session token without any calls
+	srv.SetAccountSecret("files", "second")
+	write("api_secret", "second")
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.ActiveTokens("files") != 1 || srv.Requests() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("session not refreshed after credential file change")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID after file change: %v", err)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	ep := &bridgeapi.EnvCredentialProvider{KeyVar: "BRIDGEAPI_TEST_KEY", SecretVar: "BRIDGEAPI_TEST_SECRET"}
+	defer os.Unsetenv(ep.KeyVar)
+	defer os.Unsetenv(ep.SecretVar)
+
+	os.Setenv(ep.KeyVar, "key")
+	if _, err := ep.ProvideCredential(); err == nil {
+		t.Fatalf("ProvideCredential without %s succeeded", ep.SecretVar)
+	}
+	os.Setenv(ep.SecretVar, "secret")
+	if cred, err := ep.ProvideCredential(); err != nil || cred != (bridgeapi.LoginCred{Key: "key", Secret: "secret"}) {
+		t.Fatalf("ProvideCredential = %+v, %v", cred, err)
+	}
+}
+
+func TestExecCredentials(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	xp := &bridgeapi.ExecCredentialProvider{
+		Command: "sh",
+		// Each argument reaches the helper on its own, spaces included
+		Args: []string{"-c", `echo run >> "$RUNS"; printf '{"key":"%s","secret":"%s"}' "$1" "$2"`,
+			"helper", "exec key", "exec-secret"},
+		Env: []string{"RUNS=" + runs},
+	}
+	countRuns := func() int {
+		out, _ := os.ReadFile(runs)
+		return strings.Count(string(out), "run")
+	}
+
+	cred, err := xp.ProvideCredential()
+	if err != nil || cred != (bridgeapi.LoginCred{Key: "exec key", Secret: "exec-secret"}) {
+		t.Fatalf("ProvideCredential = %+v, %v", cred, err)
+	}
+	if _, err := xp.ProvideCredential(); err != nil || countRuns() != 1 {
+		t.Fatalf("second ProvideCredential = %v with %d helper runs; want the cached result", err, countRuns())
+	}
+
+	// Credentials past their expiration are fetched again
+	expired := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	xp = &bridgeapi.ExecCredentialProvider{
+		Command: "sh",
+		Args:    []string{"-c", `echo run >> "$RUNS"; echo '{"key":"k","secret":"s","expiration_timestamp":"` + expired + `"}'`},
+		Env:     []string{"RUNS=" + runs},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := xp.ProvideCredential(); err != nil {
+			t.Fatalf("ProvideCredential with expired result: %v", err)
+		}
+	}
+	if n := countRuns(); n != 3 {
+		t.Errorf("helper runs = %d; want 3, expired results not reused", n)
+	}
+
+	xp = &bridgeapi.ExecCredentialProvider{Command: "sh", Args: []string{"-c", "exit 1"}}
+	if _, err := xp.ProvideCredential(); err == nil {
+		t.Error("ProvideCredential with a failing helper succeeded")
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jpillora/backoff"
+)
+
+// RetryPolicy controls how the Client repeats idempotent requests which
+// failed for transient reasons: network errors, rate limiting (429) and
+// server errors (5xx)
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts for a request, including
+	// the first. Values below 2 disable retries
+	MaxAttempts int
+	// Min is the delay before the first retry, doubling with jitter for each
+	// following retry up to Max
+	Min time.Duration
+	Max time.Duration
+}
+
+// DefaultRetryPolicy is used unless overridden through SetRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	Min:         500 * time.Millisecond,
+	Max:         10 * time.Second,
+}
+
+// SetRetryPolicy replaces the default policy for retrying transient API
+// failures, a zero RetryPolicy disables retries
+func SetRetryPolicy(rp RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = rp
+	}
+}
+
+// do sends req, repeating it per the client's retry policy when the request
+// is safe to repeat and the failure is transient. The final response or
+// error is returned as-is for the caller to interpret. Waits between
+// attempts end early if the request context is done.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.retry.MaxAttempts < 2 || !isIdempotent(req) {
+		return c.send(req)
+	}
+
+	delay := backoff.Backoff{
+		Min:    c.retry.Min,
+		Max:    c.retry.Max,
+		Factor: 2,
+		Jitter: true,
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(req)
+		if attempt >= c.retry.MaxAttempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := delay.Duration()
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			// Hand back the response when the wait would outlast the
+			// request, so the caller still learns why it failed
+			if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+				return resp, err
+			}
+			// Release the connection before trying again
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		c.log.Info("retrying API request", "method", req.Method, "path", req.URL.Path,
+			"attempt", attempt, "wait", wait.String(), "error", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// isIdempotent reports whether req can be repeated without side effects,
+// either by its method or by carrying an Idempotency-Key
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" && (req.Body == nil || req.GetBody != nil)
+}
+
+// shouldRetry reports whether the outcome of an attempt is transient
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// Cancellation and deadlines belong to the caller, not the API
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header of resp, given either in seconds
+// or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	val := resp.Header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+	if sec, err := strconv.Atoi(val); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+	if at, err := http.ParseTime(val); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
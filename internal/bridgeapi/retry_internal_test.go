@@ -0,0 +1,50 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestIsIdempotent(t *testing.T) {
+	for _, tc := range []struct {
+		method string
+		key    bool
+		want   bool
+	}{
+		{http.MethodGet, false, true},
+		{http.MethodHead, false, true},
+		{http.MethodDelete, false, true},
+		{http.MethodPost, false, false},
+		{http.MethodPatch, false, false},
+		{http.MethodPut, false, false},
+		{http.MethodPost, true, true},
+		{http.MethodPatch, true, true},
+	} {
+		req, err := http.NewRequest(tc.method, "https://api.example.com/clusters", bytes.NewReader([]byte("{}")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.key {
+			req.Header.Set("Idempotency-Key", "key")
+		}
+		if got := isIdempotent(req); got != tc.want {
+			t.Errorf("isIdempotent(%s, key %t) = %t; want %t", tc.method, tc.key, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+func TestRetry(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("retry", "cbkey_retry")
+	client := newTestClient(t, srv, "retry", "cbkey_retry")
+	defer client.Close()
+	ctx := context.Background()
+	id := srv.AddCluster(bridgeapi.ClusterDetail{Name: "retried", TeamID: acctID})
+
+	// requests returns the number of requests made by call
+	requests := func(call func() error) (int, error) {
+		before := srv.Requests()
+		err := call()
+		return srv.Requests() - before, err
+	}
+
+	// A single server error is absorbed by the client retrying
+	srv.InjectFault(bridgetest.Fault{Method: http.MethodGet, Path: "/account", Status: http.StatusServiceUnavailable, Times: 1})
+	n, err := requests(func() error { _, err := client.DefaultTeamID(ctx); return err })
+	if err != nil || n != 2 {
+		t.Fatalf("DefaultTeamID with one 503 = %d requests, %v; want 2 and success", n, err)
+	}
+
+	// Persistent errors are returned once the attempts run out
+	srv.InjectFault(bridgetest.Fault{Path: "/account", Status: http.StatusInternalServerError})
+	n, err = requests(func() error { _, err := client.DefaultTeamID(ctx); return err })
+	if !errors.Is(err, bridgeapi.ErrorServerError) || n != 3 {
+		t.Fatalf("DefaultTeamID with 500s = %d requests, %v; want 3 and ErrorServerError", n, err)
+	}
+	srv.ClearFaults()
+
+	// Deletes are idempotent and retried
+	srv.InjectFault(bridgetest.Fault{Method: http.MethodDelete, Path: "/clusters/" + id, Status: http.StatusBadGateway, Times: 1})
+	n, err = requests(func() error { return client.DeleteCluster(ctx, id) })
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteCluster with one 502 = %d requests, %v; want 2 and success", n, err)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("once", "cbkey_once")
+	client := newTestClient(t, srv, "once", "cbkey_once")
+	defer client.Close()
+	ctx := context.Background()
+	id := srv.AddCluster(bridgeapi.ClusterDetail{Name: "once", TeamID: acctID})
+
+	// Creating may have taken effect despite the error, it isn't repeated
+	srv.InjectFault(bridgetest.Fault{Method: http.MethodPost, Path: "/clusters", Status: http.StatusServiceUnavailable, Times: 1})
+	before := srv.Requests()
+	_, err := client.CreateCluster(ctx, bridgeapi.CreateRequest{
+		Name: "not-repeated", TeamID: acctID, Plan: "hobby-2", Provider: "aws", Region: "us-east-1",
+	})
+	if !errors.Is(err, bridgeapi.ErrorServerError) || srv.Requests()-before != 1 {
+		t.Errorf("CreateCluster with one 503 = %d requests, %v; want 1 and ErrorServerError", srv.Requests()-before, err)
+	}
+
+	srv.InjectFault(bridgetest.Fault{Method: http.MethodPost, Path: "/clusters/" + id, Status: http.StatusServiceUnavailable, Times: 1})
+	before = srv.Requests()
+	err = client.UpdateCluster(ctx, id, bridgeapi.UpdateRequest{StorageGB: 20})
+	if !errors.Is(err, bridgeapi.ErrorServerError) || srv.Requests()-before != 1 {
+		t.Errorf("UpdateCluster with one 503 = %d requests, %v; want 1 and ErrorServerError", srv.Requests()-before, err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	srv.AddAccount("after", "cbkey_after")
+	client := newTestClient(t, srv, "after", "cbkey_after")
+	defer client.Close()
+	ctx := context.Background()
+
+	// The wait requested by the API replaces the much shorter backoff
+	srv.InjectFault(bridgetest.Fault{Path: "/account", Status: http.StatusTooManyRequests, RetryAfter: "1", Times: 1})
+	start := time.Now()
+	if _, err := client.DefaultTeamID(ctx); err != nil {
+		t.Fatalf("DefaultTeamID after 429: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("DefaultTeamID retried after %s; want the 1s Retry-After", elapsed)
+	}
+
+	// A wait past the caller's deadline returns the response right away
+	srv.InjectFault(bridgetest.Fault{Path: "/account", Status: http.StatusTooManyRequests, RetryAfter: "30", Times: 1})
+	short, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	start = time.Now()
+	if _, err := client.DefaultTeamID(short); !errors.Is(err, bridgeapi.ErrorRateLimited) {
+		t.Fatalf("DefaultTeamID with Retry-After past deadline = %v; want ErrorRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("DefaultTeamID gave up after %s; want no wait", elapsed)
+	}
+}
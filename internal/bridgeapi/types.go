@@ -30,6 +30,7 @@ var (
 	ErrorFailedRenew  = errors.New("Failed to establish renewed login")
 	ErrorInvalidCreds = errors.New("Invalid credentials for API login")
 	ErrorUnstarted    = errors.New("Successful login not yet achieved")
+	ErrorNoCreds      = errors.New("API credentials unavailable")
 )
 
 type LoginState int
@@ -52,6 +53,22 @@ const (
 	LoginInvalidCreds
 )
 
+func (ls LoginState) String() string {
+	switch ls {
+	case LoginUnstarted:
+		return "unstarted"
+	case LoginFailed:
+		return "failed"
+	case LoginActive:
+		return "active"
+	case LoginInactive:
+		return "inactive"
+	case LoginInvalidCreds:
+		return "invalid credentials"
+	}
+	return fmt.Sprintf("unknown (%d)", int(ls))
+}
+
 // Intentionally not exposed for usage outside package
 func (ls LoginState) toError() error {
 	switch ls {
@@ -72,11 +89,31 @@ func (ls LoginState) toError() error {
 type ClusterState string
 
 const (
-	StateUnknown  ClusterState = "unknown"
-	StateCreating ClusterState = "creating"
-	StateReady    ClusterState = "ready"
+	StateUnknown     ClusterState = "unknown"
+	StateCreating    ClusterState = "creating"
+	StateReady       ClusterState = "ready"
+	StateDestroying  ClusterState = "destroying"
+	StateFailed      ClusterState = "failed"
+	StateFinalizing  ClusterState = "finalizing"
+	StateMaintenance ClusterState = "maintenance"
+	StateReplaying   ClusterState = "replaying"
+	StateRestarting  ClusterState = "restarting"
+	StateResuming    ClusterState = "resuming"
+	StateSuspended   ClusterState = "suspended"
+	StateSuspending  ClusterState = "suspending"
+	StateUpgrading   ClusterState = "upgrading"
 )
 
+// ProvisioningFailed reports whether a cluster found in this state while
+// being provisioned will never become ready
+func (cs ClusterState) ProvisioningFailed() bool {
+	switch cs {
+	case StateFailed, StateDestroying, StateSuspending, StateSuspended:
+		return true
+	}
+	return false
+}
+
 type CreateRequest struct {
 	Name             string `json:"name"`
 	TeamID           string `json:"team_id"`
@@ -89,6 +126,26 @@ type CreateRequest struct {
 	Trial            bool   `json:"is_trial"`
 }
 
+// UpdateRequest describes a change to an existing cluster, zero-valued
+// fields are left unchanged. A higher PGMajorVersion starts an in-place
+// major version upgrade.
+type UpdateRequest struct {
+	Plan             string `json:"plan_id,omitempty"`
+	StorageGB        int    `json:"storage,omitempty"`
+	HighAvailability *bool  `json:"is_ha,omitempty"`
+	PGMajorVersion   int    `json:"major_version,omitempty"`
+}
+
+// ReplicaRequest describes a read replica of an existing cluster. Blank
+// fields take the value of the primary, provider and region may differ from
+// it for a cross-region replica
+type ReplicaRequest struct {
+	Name     string `json:"name"`
+	Plan     string `json:"plan_id,omitempty"`
+	Provider string `json:"provider_id,omitempty"`
+	Region   string `json:"region_id,omitempty"`
+}
+
 type ClusterList struct {
 	Clusters []ClusterDetail `json:"clusters"`
 }
@@ -98,10 +155,12 @@ type ClusterDetail struct {
 	Created          time.Time       `json:"created_at"`
 	ID               string          `json:"id"`
 	HighAvailability bool            `json:"is_ha"`
+	Host             string          `json:"host"`
 	PGMajorVersion   int             `json:"major_version"`
 	MemoryGB         int             `json:"memory"`
 	Name             string          `json:"name"`
 	OldestBackup     time.Time       `json:"oldest_backup"`
+	PlanID           string          `json:"plan_id"`
 	ProviderID       string          `json:"provider_id"`
 	RegionID         string          `json:"region_id"`
 	State            string          `json:"state"` // Leave as string until graceful error handling
@@ -117,6 +176,35 @@ type ConnectionRole struct {
 	URI      string `json:"uri"`
 }
 
+type RoleRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+type ProviderList struct {
+	Providers []Provider `json:"providers"`
+}
+
+// Provider describes a cloud provider in the Bridge catalog
+type Provider struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"display_name"`
+	Regions     []Region `json:"regions"`
+	Plans       []Plan   `json:"plans"`
+}
+
+type Region struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	Location    string `json:"location"`
+}
+
+type Plan struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	CPU         int    `json:"cpu"`
+	MemoryGB    int    `json:"memory"`
+}
+
 type Account struct {
 	ID            string `json:"id"`
 	DefaultTeamID string `json:"default_team_id"`
@@ -0,0 +1,349 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgepoll
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+)
+
+// ClientFunc provides the API client for one round of polling, along with a
+// function to call once done with it
+type ClientFunc func(ctx context.Context) (bridgeapi.Interface, func(), error)
+
+// Poller lists the clusters of one account on an adaptive interval and
+// caches their details by ID and name. Objects tracked through Track are
+// sent to their channel whenever the cluster they follow changes, suitable
+// for a controller watching a source.Channel.
+type Poller struct {
+	getClient   ClientFunc
+	minInterval time.Duration
+	maxInterval time.Duration
+	log         logr.Logger
+	kick        chan struct{}
+
+	mu      sync.RWMutex
+	byID    map[string]bridgeapi.ClusterDetail
+	byName  map[string]string // cluster ID by name
+	roles   map[string]cachedRole
+	synced  bool
+	lastErr error
+	tracked map[trackKey]*tracker
+}
+
+type cachedRole struct {
+	role    bridgeapi.ConnectionRole
+	fetched time.Time
+}
+
+type trackKey struct {
+	events chan<- event.GenericEvent
+	object types.NamespacedName
+}
+
+type tracker struct {
+	object client.Object
+	// clusterID is followed when known, otherwise name
+	clusterID string
+	name      string
+	// active marks an object expecting the cluster to change soon
+	active bool
+}
+
+func newPoller(getClient ClientFunc, minInterval, maxInterval time.Duration, logger logr.Logger) *Poller {
+	return &Poller{
+		getClient:   getClient,
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+		log:         logger,
+		kick:        make(chan struct{}, 1),
+		byID:        map[string]bridgeapi.ClusterDetail{},
+		byName:      map[string]string{},
+		roles:       map[string]cachedRole{},
+		tracked:     map[trackKey]*tracker{},
+	}
+}
+
+// Track registers obj to be sent to events when the cluster with clusterID,
+// or with name if the ID is not yet known, changes. Setting active polls at
+// the shortest interval, for objects waiting on provisioning or updates.
+// Tracking an object again replaces its earlier registration.
+func (p *Poller) Track(events chan<- event.GenericEvent, obj client.Object, clusterID, name string, active bool) {
+	key := trackKey{events: events, object: client.ObjectKeyFromObject(obj)}
+	t := &tracker{
+		object:    obj.DeepCopyObject().(client.Object),
+		clusterID: clusterID,
+		name:      name,
+		active:    active,
+	}
+
+	p.mu.Lock()
+	prev, ok := p.tracked[key]
+	p.tracked[key] = t
+	p.mu.Unlock()
+
+	if !ok || prev.clusterID != clusterID || prev.name != name || (active && !prev.active) {
+		p.Refresh()
+	}
+}
+
+// Untrack stops sending events for obj
+func (p *Poller) Untrack(events chan<- event.GenericEvent, obj client.Object) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.tracked, trackKey{events: events, object: client.ObjectKeyFromObject(obj)})
+}
+
+// Refresh requests a poll as soon as possible, as after changing a cluster
+func (p *Poller) Refresh() {
+	select {
+	case p.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Lookup returns the cached detail of the cluster with clusterID, or with
+// name if clusterID is empty. Found is false until the first poll completes
+// or if no such cluster was listed, tracked objects are notified either
+// way. The error of the last poll, if it failed, is returned instead.
+func (p *Poller) Lookup(clusterID, name string) (det bridgeapi.ClusterDetail, found bool, err error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.lastErr != nil {
+		return bridgeapi.ClusterDetail{}, false, p.lastErr
+	}
+	det, found = p.lookup(clusterID, name)
+	return det, found, nil
+}
+
+// Synced reports whether the clusters have been listed at least once, until
+// then Lookup finds nothing
+func (p *Poller) Synced() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.synced
+}
+
+// lookup requires the lock to be held
+func (p *Poller) lookup(clusterID, name string) (bridgeapi.ClusterDetail, bool) {
+	if clusterID == "" {
+		id, ok := p.byName[name]
+		if !ok {
+			return bridgeapi.ClusterDetail{}, false
+		}
+		clusterID = id
+	}
+	det, ok := p.byID[clusterID]
+	return det, ok
+}
+
+// DefaultConnRole returns the default connection role of a cluster, cached
+// for the longest polling interval
+func (p *Poller) DefaultConnRole(ctx context.Context, clusterID string) (bridgeapi.ConnectionRole, error) {
+	p.mu.RLock()
+	cached, ok := p.roles[clusterID]
+	p.mu.RUnlock()
+	if ok && time.Since(cached.fetched) < p.maxInterval {
+		return cached.role, nil
+	}
+
+	bc, done, err := p.getClient(ctx)
+	if err != nil {
+		return bridgeapi.ConnectionRole{}, err
+	}
+	defer done()
+	role, err := bc.DefaultConnRole(ctx, clusterID)
+	if err != nil {
+		return role, err
+	}
+
+	p.mu.Lock()
+	p.roles[clusterID] = cachedRole{role: role, fetched: time.Now()}
+	p.mu.Unlock()
+	return role, nil
+}
+
+// run polls until ctx is done. Nothing is polled while no objects are
+// tracked, otherwise the interval doubles from the minimum up to the
+// maximum while no tracked object is active.
+func (p *Poller) run(ctx context.Context) {
+	interval := p.minInterval
+	for {
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if !p.idle() {
+			if err := p.poll(ctx); err == nil && p.active() {
+				interval = p.minInterval
+			} else {
+				interval *= 2
+				if interval > p.maxInterval {
+					interval = p.maxInterval
+				}
+			}
+			timer = time.NewTimer(interval)
+			expired = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-p.kick:
+			interval = p.minInterval
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// idle reports whether no objects are tracked
+func (p *Poller) idle() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.tracked) == 0
+}
+
+// active reports whether any tracked object is waiting on a change, or
+// follows a cluster that is missing or not ready
+func (p *Poller) active() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, t := range p.tracked {
+		if t.active {
+			return true
+		}
+		if det, ok := p.lookup(t.clusterID, t.name); !ok || det.State != string(bridgeapi.StateReady) {
+			return true
+		}
+	}
+	return false
+}
+
+// poll lists all clusters of the account, replaces the cache and notifies
+// tracked objects whose cluster changed
+func (p *Poller) poll(ctx context.Context) error {
+	bc, done, err := p.getClient(ctx)
+	if err == nil {
+		var list bridgeapi.ClusterList
+		list, err = bc.ListAllClusters(ctx)
+		done()
+		if err == nil {
+			p.update(ctx, list)
+			return nil
+		}
+	}
+
+	p.log.Error(err, "error polling clusters")
+	p.mu.Lock()
+	first := p.lastErr == nil
+	p.lastErr = err
+	notify := p.notifyAll(first)
+	p.mu.Unlock()
+	p.send(ctx, notify)
+	return err
+}
+
+func (p *Poller) update(ctx context.Context, list bridgeapi.ClusterList) {
+	byID := make(map[string]bridgeapi.ClusterDetail, len(list.Clusters))
+	byName := make(map[string]string, len(list.Clusters))
+	for _, det := range list.Clusters {
+		byID[det.ID] = det
+		if _, dup := byName[det.Name]; !dup {
+			byName[det.Name] = det.ID
+		}
+	}
+
+	p.mu.Lock()
+	oldID, oldName := p.byID, p.byName
+	wasSynced, hadErr := p.synced, p.lastErr != nil
+	p.byID, p.byName = byID, byName
+	p.synced, p.lastErr = true, nil
+
+	var notify []trackKey
+	for key, t := range p.tracked {
+		id := t.clusterID
+		if id == "" {
+			id = oldName[t.name]
+		}
+		before, wasFound := oldID[id]
+		after, isFound := p.lookup(t.clusterID, t.name)
+		if !wasSynced || hadErr || wasFound != isFound || !reflect.DeepEqual(before, after) {
+			notify = append(notify, key)
+		}
+	}
+	for id := range p.roles {
+		if _, ok := byID[id]; !ok {
+			delete(p.roles, id)
+		}
+	}
+	objects := p.objects(notify)
+	p.mu.Unlock()
+
+	p.send(ctx, objects)
+}
+
+// notifyAll returns all tracked objects if all is set, requires the lock
+func (p *Poller) notifyAll(all bool) []trackedObject {
+	if !all {
+		return nil
+	}
+	keys := make([]trackKey, 0, len(p.tracked))
+	for key := range p.tracked {
+		keys = append(keys, key)
+	}
+	return p.objects(keys)
+}
+
+type trackedObject struct {
+	events chan<- event.GenericEvent
+	object client.Object
+}
+
+// objects resolves keys to the objects to send, requires the lock
+func (p *Poller) objects(keys []trackKey) []trackedObject {
+	objs := make([]trackedObject, 0, len(keys))
+	for _, key := range keys {
+		objs = append(objs, trackedObject{events: key.events, object: p.tracked[key].object})
+	}
+	return objs
+}
+
+// send delivers events outside the lock, the receiving controllers may be
+// slow to drain their channels
+func (p *Poller) send(ctx context.Context, objs []trackedObject) {
+	for _, o := range objs {
+		select {
+		case o.events <- event.GenericEvent{Object: o.object}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
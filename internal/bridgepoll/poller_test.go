@@ -0,0 +1,114 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgepoll
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+func TestPollerTracksProvisioning(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	teamID := srv.AddAccount("poller", "secret")
+	client, err := bridgeapi.NewClient(srv.APIURL(), bridgeapi.LoginCred{Key: "poller", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := &Registry{MinInterval: 10 * time.Millisecond, MaxInterval: 40 * time.Millisecond}
+	poller := reg.For("poller", func(context.Context) (bridgeapi.Interface, func(), error) {
+		return client, func() {}, nil
+	})
+	if again := reg.For("poller", nil); again != poller {
+		t.Fatal("For returned a new poller for the same account")
+	}
+	go reg.Start(ctx)
+
+	_, err = client.CreateCluster(ctx, bridgeapi.CreateRequest{
+		Name:     "polled",
+		TeamID:   teamID,
+		Plan:     "hobby-2",
+		Provider: "aws",
+		Region:   "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	events := make(chan event.GenericEvent)
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "polled"}}
+	poller.Track(events, obj, "", "polled", true)
+
+	waitFor := func(state bridgeapi.ClusterState) bridgeapi.ClusterDetail {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case ev := <-events:
+				if ev.Object.GetName() != "polled" {
+					t.Fatalf("event for unexpected object %s", ev.Object.GetName())
+				}
+				det, found, err := poller.Lookup("", "polled")
+				if err != nil {
+					t.Fatalf("Lookup: %v", err)
+				}
+				if found && det.State == string(state) {
+					return det
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for cluster state %s", state)
+			}
+		}
+	}
+
+	det := waitFor(bridgeapi.StateCreating)
+	srv.Advance(bridgetest.DefaultProvisionTime)
+	waitFor(bridgeapi.StateReady)
+
+	// Requests stop while nothing is tracked
+	poller.Untrack(events, obj)
+	time.Sleep(50 * time.Millisecond)
+	before := srv.Requests()
+	time.Sleep(100 * time.Millisecond)
+	if after := srv.Requests(); after != before {
+		t.Fatalf("untracked poller made %d requests", after-before)
+	}
+
+	role, err := poller.DefaultConnRole(ctx, det.ID)
+	if err != nil || role.Password == "" {
+		t.Fatalf("DefaultConnRole = %+v, %v", role, err)
+	}
+	before = srv.Requests()
+	if cached, err := poller.DefaultConnRole(ctx, det.ID); err != nil || cached != role {
+		t.Fatalf("cached DefaultConnRole = %+v, %v; want %+v", cached, err, role)
+	}
+	if srv.Requests() != before {
+		t.Fatal("DefaultConnRole not served from cache")
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgepoll
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// DefaultMinInterval is the polling interval while clusters are being
+	// provisioned or changed
+	DefaultMinInterval = 10 * time.Second
+
+	// DefaultMaxInterval is the polling interval reached while all tracked
+	// clusters are stable
+	DefaultMaxInterval = 2 * time.Minute
+)
+
+// Registry hands out one Poller per account. It is a manager Runnable,
+// pollers only run once the Registry has been started.
+type Registry struct {
+	// MinInterval defaults to DefaultMinInterval
+	MinInterval time.Duration
+	// MaxInterval defaults to DefaultMaxInterval
+	MaxInterval time.Duration
+	// Log receives polling errors, discarded if unset
+	Log logr.Logger
+
+	mu      sync.Mutex
+	ctx     context.Context
+	pollers map[string]*Poller
+}
+
+// For returns the poller for the account identified by key, creating it
+// with getClient if needed. Callers sharing a registry keep their keys
+// apart, as a poller keeps the getClient it was created with.
+func (r *Registry) For(key string, getClient ClientFunc) *Poller {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.pollers[key]; ok {
+		return p
+	}
+
+	logger := r.Log
+	if logger.GetSink() == nil {
+		logger = logr.Discard()
+	}
+	p := newPoller(getClient, r.minInterval(), r.MaxIntervalOrDefault(),
+		logger.WithValues("account", key))
+	if r.pollers == nil {
+		r.pollers = map[string]*Poller{}
+	}
+	r.pollers[key] = p
+	if r.ctx != nil {
+		go p.run(r.ctx)
+	}
+	return p
+}
+
+// Start runs the pollers until ctx is done
+func (r *Registry) Start(ctx context.Context) error {
+	r.mu.Lock()
+	r.ctx = ctx
+	for _, p := range r.pollers {
+		go p.run(ctx)
+	}
+	r.mu.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+// MaxIntervalOrDefault returns the longest polling interval, the most a
+// cached cluster or connection role may lag behind the API
+func (r *Registry) MaxIntervalOrDefault() time.Duration {
+	if r.MaxInterval > 0 {
+		return r.MaxInterval
+	}
+	return DefaultMaxInterval
+}
+
+func (r *Registry) minInterval() time.Duration {
+	if r.MinInterval > 0 {
+		return r.MinInterval
+	}
+	return DefaultMinInterval
+}
@@ -0,0 +1,821 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgetest
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+)
+
+const (
+	// DefaultProvisionTime is how long a new cluster stays in the creating
+	// state, as measured by the server clock
+	DefaultProvisionTime = 5 * time.Minute
+
+	// tokenLifetime is the expires_in value, in seconds, of issued tokens
+	tokenLifetime = 3600
+)
+
+// defaultPlans are offered by every provider in the default catalog
+var defaultPlans = []bridgeapi.Plan{
+	{ID: "hobby-2", DisplayName: "Hobby-2", CPU: 1, MemoryGB: 2},
+	{ID: "hobby-4", DisplayName: "Hobby-4", CPU: 1, MemoryGB: 4},
+	{ID: "standard-8", DisplayName: "Standard-8", CPU: 2, MemoryGB: 8},
+	{ID: "standard-16", DisplayName: "Standard-16", CPU: 4, MemoryGB: 16},
+}
+
+// DefaultProviders is the catalog served by a new Server
+var DefaultProviders = []bridgeapi.Provider{
+	{
+		ID:          "aws",
+		DisplayName: "AWS",
+		Regions: []bridgeapi.Region{
+			{ID: "us-east-1", DisplayName: "US East 1", Location: "N. Virginia"},
+			{ID: "us-west-2", DisplayName: "US West 2", Location: "Oregon"},
+			{ID: "eu-west-1", DisplayName: "EU West 1", Location: "Ireland"},
+		},
+		Plans: defaultPlans,
+	},
+	{
+		ID:          "gcp",
+		DisplayName: "GCP",
+		Regions: []bridgeapi.Region{
+			{ID: "us-central1", DisplayName: "US Central 1", Location: "Iowa"},
+			{ID: "europe-west3", DisplayName: "Europe West 3", Location: "Frankfurt"},
+		},
+		Plans: defaultPlans,
+	},
+	{
+		ID:          "azure",
+		DisplayName: "Azure",
+		Regions: []bridgeapi.Region{
+			{ID: "eastus", DisplayName: "East US", Location: "Virginia"},
+			{ID: "westeurope", DisplayName: "West Europe", Location: "Netherlands"},
+		},
+		Plans: defaultPlans,
+	},
+}
+
+// Server is an in-memory stand-in for the Crunchy Bridge API, served over
+// HTTP by an httptest.Server. Clusters move from creating to ready as the
+// server clock, advanced only through Advance, passes their provisioning
+// time. Deleted clusters are removed at once unless SetDeletionTime is used.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	now           time.Time
+	provisionTime time.Duration
+	deletionTime  time.Duration
+	accounts      map[string]*account // by API key
+	tokens        map[string]*account // by bearer token
+	tokenIDs      map[string]string   // bearer token by token ID
+	clusters      map[string]*cluster // by cluster ID
+	providers     []bridgeapi.Provider
+	faults        []*Fault
+	requests      int
+}
+
+type account struct {
+	key    string
+	secret string
+	id     string
+	teams  []team
+}
+
+type team struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Personal bool   `json:"is_personal"`
+}
+
+type cluster struct {
+	detail  bridgeapi.ClusterDetail
+	readyAt time.Time
+	roles   map[string]bridgeapi.ConnectionRole
+	// primary identifies the cluster a read replica follows, blank for
+	// primaries
+	primary string
+	// upgradeTo is the major version an upgrading cluster reports once ready
+	upgradeTo int
+	// removeAt is when a destroying cluster disappears, zero for clusters
+	// not being deleted
+	removeAt time.Time
+}
+
+// Fault describes a failure to inject into requests matching Method and
+// Path, the zero value of either matching any request
+type Fault struct {
+	// Method is the HTTP method to match
+	Method string
+	// Path is matched as a prefix of the request path
+	Path string
+	// Status, when set, replaces the normal response with an error message
+	// carrying this status code
+	Status int
+	// RetryAfter, when set, is sent as the Retry-After header with Status
+	RetryAfter string
+	// Header is added to the response, with or without Status
+	Header http.Header
+	// Latency delays the response by the given (wall clock) duration
+	Latency time.Duration
+	// Times limits the fault to the given number of requests, zero applies
+	// it until ClearFaults is called
+	Times int
+}
+
+// NewServer starts a server with no accounts. The caller is expected to
+// call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		now:           time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+		provisionTime: DefaultProvisionTime,
+		accounts:      map[string]*account{},
+		tokens:        map[string]*account{},
+		tokenIDs:      map[string]string{},
+		clusters:      map[string]*cluster{},
+		providers:     DefaultProviders,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// APIURL returns the server URL in the form expected by bridgeapi.NewClient
+func (s *Server) APIURL() *url.URL {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// AddAccount registers an API key and secret, returning the account ID
+// which also identifies the account's personal team. Secrets prefixed with
+// "cbkey_" are accepted directly as bearer tokens, as with Crunchy Bridge.
+func (s *Server) AddAccount(key, secret string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acct := &account{
+		key:    key,
+		secret: secret,
+		id:     newID(),
+	}
+	acct.teams = []team{{ID: acct.id, Name: "Personal Team", Personal: true}}
+	s.accounts[key] = acct
+	if strings.HasPrefix(secret, "cbkey_") {
+		s.tokens[secret] = acct
+	}
+	return acct.id
+}
+
+// SetAccountSecret replaces the API secret of the account registered under
+// key, as when a key is rotated. Tokens already issued remain valid until
+// revoked.
+func (s *Server) SetAccountSecret(key, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acct, ok := s.accounts[key]
+	if !ok {
+		panic("bridgetest: no account for key " + key)
+	}
+	acct.secret = secret
+	if strings.HasPrefix(secret, "cbkey_") {
+		s.tokens[secret] = acct
+	}
+}
+
+// ActiveTokens returns the number of exchanged access tokens which have not
+// been revoked for the account registered under key
+func (s *Server) ActiveTokens(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, token := range s.tokenIDs {
+		if acct := s.tokens[token]; acct != nil && acct.key == key {
+			count++
+		}
+	}
+	return count
+}
+
+// AddTeam creates a team visible to the account registered under key and
+// returns its ID
+func (s *Server) AddTeam(key, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acct, ok := s.accounts[key]
+	if !ok {
+		panic("bridgetest: no account for key " + key)
+	}
+	t := team{ID: newID(), Name: name}
+	acct.teams = append(acct.teams, t)
+	return t.ID
+}
+
+// AddCluster stores an existing cluster, filling in the ID, host and
+// timestamps when blank, and returns its ID. The cluster's state is left as
+// given, and it has the default postgres role like any created cluster.
+func (s *Server) AddCluster(det bridgeapi.ClusterDetail) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if det.ID == "" {
+		det.ID = newID()
+	}
+	if det.Host == "" {
+		det.Host = hostFor(det.ID)
+	}
+	if det.Created.IsZero() {
+		det.Created = s.now
+	}
+	if det.Updated.IsZero() {
+		det.Updated = s.now
+	}
+	c := &cluster{
+		detail:  det,
+		readyAt: s.now,
+		roles:   map[string]bridgeapi.ConnectionRole{},
+	}
+	c.roles["postgres"] = s.newRole(c, "postgres", newID())
+	s.clusters[det.ID] = c
+	return det.ID
+}
+
+// Cluster returns the current detail of the identified cluster
+func (s *Server) Cluster(id string) (bridgeapi.ClusterDetail, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.clusters[id]
+	if !ok {
+		return bridgeapi.ClusterDetail{}, false
+	}
+	return s.detail(c), true
+}
+
+// SetClusterState overrides the state reported for the identified cluster
+func (s *Server) SetClusterState(id string, state bridgeapi.ClusterState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clusters[id]; ok {
+		c.detail.State = string(state)
+		c.readyAt = s.now
+	}
+}
+
+// SetRolePassword changes the password of a role, as when it is rotated
+// outside the operator
+func (s *Server) SetRolePassword(clusterID, name, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.clusters[clusterID]; ok {
+		if _, ok := c.roles[name]; ok {
+			c.roles[name] = s.newRole(c, name, password)
+		}
+	}
+}
+
+// SetProvisionTime sets how long clusters created from now on remain in
+// the creating state
+func (s *Server) SetProvisionTime(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provisionTime = d
+}
+
+// SetDeletionTime sets how long clusters deleted from now on remain listed
+// in the destroying state, zero removing them at once
+func (s *Server) SetDeletionTime(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletionTime = d
+}
+
+// Now returns the current server clock
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the server clock forward by d, removing destroying
+// clusters whose deletion time has passed
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+	for id, c := range s.clusters {
+		if !c.removeAt.IsZero() && !s.now.Before(c.removeAt) {
+			delete(s.clusters, id)
+		}
+	}
+}
+
+// InjectFault adds a fault to apply to matching requests, the earliest
+// added fault wins where several match
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the number of requests received so far
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		for name, vals := range fault.Header {
+			w.Header()[name] = vals
+		}
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			if fault.RetryAfter != "" {
+				w.Header().Set("Retry-After", fault.RetryAfter)
+			}
+			writeMessage(w, fault.Status, "injected fault")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodPost && r.URL.Path == "/access-tokens" {
+		s.issueToken(w, r)
+		return
+	}
+
+	acct := s.authenticate(r)
+	if acct == nil {
+		writeMessage(w, http.StatusUnauthorized, "invalid or missing bearer token")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/account":
+		writeJSON(w, http.StatusOK, bridgeapi.Account{ID: acct.id})
+	case r.Method == http.MethodDelete && len(parts) == 2 && parts[0] == "access-tokens":
+		s.revokeToken(w, acct, parts[1])
+	case r.Method == http.MethodGet && r.URL.Path == "/teams":
+		writeJSON(w, http.StatusOK, map[string][]team{"teams": acct.teams})
+	case r.Method == http.MethodGet && r.URL.Path == "/providers":
+		writeJSON(w, http.StatusOK, bridgeapi.ProviderList{Providers: s.providers})
+	case r.URL.Path == "/clusters":
+		switch r.Method {
+		case http.MethodGet:
+			s.listClusters(w, r, acct)
+		case http.MethodPost:
+			s.createCluster(w, r, acct)
+		default:
+			writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	case len(parts) >= 2 && parts[0] == "clusters":
+		c, ok := s.clusters[parts[1]]
+		if !ok || !acct.canSee(c.detail.TeamID) {
+			writeMessage(w, http.StatusNotFound, "cluster not found")
+			return
+		}
+		s.serveCluster(w, r, c, parts[2:])
+	default:
+		writeMessage(w, http.StatusNotFound, "not found")
+	}
+}
+
+// matchFault returns the first fault applying to r, using up one of its
+// allowed occurrences
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		match := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &match
+	}
+	return nil
+}
+
+func (s *Server) issueToken(w http.ResponseWriter, r *http.Request) {
+	key, secret, ok := r.BasicAuth()
+	acct, found := s.accounts[key]
+	if !ok || !found || acct.secret != secret {
+		writeMessage(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	token, id := newID(), newID()
+	s.tokens[token] = acct
+	s.tokenIDs[id] = token
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"expires_in":   tokenLifetime,
+		"id":           id,
+	})
+}
+
+func (s *Server) revokeToken(w http.ResponseWriter, acct *account, id string) {
+	token, ok := s.tokenIDs[id]
+	if !ok || s.tokens[token] != acct {
+		writeMessage(w, http.StatusNotFound, "access token not found")
+		return
+	}
+	delete(s.tokens, token)
+	delete(s.tokenIDs, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) authenticate(r *http.Request) *account {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return nil
+	}
+	return s.tokens[token]
+}
+
+func (s *Server) listClusters(w http.ResponseWriter, r *http.Request, acct *account) {
+	teamID := r.URL.Query().Get("team_id")
+	if teamID == "" {
+		teamID = acct.id
+	}
+	if !acct.canSee(teamID) {
+		writeMessage(w, http.StatusForbidden, "team not visible to account")
+		return
+	}
+
+	list := bridgeapi.ClusterList{Clusters: []bridgeapi.ClusterDetail{}}
+	for _, c := range s.clusters {
+		// Replicas are listed with their primary
+		if c.detail.TeamID == teamID && c.primary == "" {
+			list.Clusters = append(list.Clusters, s.detail(c))
+		}
+	}
+	sort.Slice(list.Clusters, func(i, j int) bool {
+		return list.Clusters[i].Name < list.Clusters[j].Name
+	})
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request, acct *account) {
+	var req bridgeapi.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMessage(w, http.StatusBadRequest, "malformed request body")
+		return
+	}
+	if req.Name == "" || req.Plan == "" || req.Provider == "" || req.Region == "" {
+		writeMessage(w, http.StatusBadRequest, "name, plan_id, provider_id and region_id are required")
+		return
+	}
+	if req.TeamID == "" {
+		req.TeamID = acct.id
+	}
+	if !acct.canSee(req.TeamID) {
+		writeMessage(w, http.StatusForbidden, "team not visible to account")
+		return
+	}
+	for _, c := range s.clusters {
+		if c.detail.TeamID == req.TeamID && c.detail.Name == req.Name {
+			writeMessage(w, http.StatusConflict, "cluster name already in use")
+			return
+		}
+	}
+
+	id := newID()
+	c := &cluster{
+		detail: bridgeapi.ClusterDetail{
+			ID:               id,
+			Name:             req.Name,
+			TeamID:           req.TeamID,
+			PlanID:           req.Plan,
+			StorageGB:        req.StorageGB,
+			ProviderID:       req.Provider,
+			RegionID:         req.Region,
+			PGMajorVersion:   req.PGMajorVersion,
+			HighAvailability: req.HighAvailability,
+			Host:             hostFor(id),
+			State:            string(bridgeapi.StateCreating),
+			Created:          s.now,
+			Updated:          s.now,
+		},
+		readyAt: s.now.Add(s.provisionTime),
+		roles:   map[string]bridgeapi.ConnectionRole{},
+	}
+	c.roles["postgres"] = s.newRole(c, "postgres", newID())
+	s.clusters[id] = c
+	writeJSON(w, http.StatusCreated, c.detail)
+}
+
+// serveCluster handles requests below /clusters/{id}, rest holding the
+// remaining path segments
+func (s *Server) serveCluster(w http.ResponseWriter, r *http.Request, c *cluster, rest []string) {
+	s.refresh(c)
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.detail(c))
+	case len(rest) == 0 && r.Method == http.MethodDelete:
+		for _, rc := range append(s.replicas(c), c) {
+			s.remove(rc)
+		}
+		writeJSON(w, http.StatusOK, c.detail)
+	case len(rest) == 1 && rest[0] == "upgrade" && r.Method == http.MethodPost:
+		var req bridgeapi.UpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeMessage(w, http.StatusBadRequest, "malformed request body")
+			return
+		}
+		if req.PGMajorVersion != 0 && req.PGMajorVersion <= c.detail.PGMajorVersion {
+			writeMessage(w, http.StatusBadRequest, "major version can only be upgraded")
+			return
+		}
+		if req.Plan != "" {
+			c.detail.PlanID = req.Plan
+		}
+		if req.StorageGB != 0 {
+			c.detail.StorageGB = req.StorageGB
+		}
+		if req.HighAvailability != nil {
+			c.detail.HighAvailability = *req.HighAvailability
+		}
+		if req.PGMajorVersion != 0 {
+			// Major upgrades take as long as provisioning
+			c.detail.State = string(bridgeapi.StateUpgrading)
+			c.upgradeTo = req.PGMajorVersion
+			c.readyAt = s.now.Add(s.provisionTime)
+		}
+		c.detail.Updated = s.now
+		writeJSON(w, http.StatusOK, c.detail)
+	case len(rest) == 1 && rest[0] == "roles" && r.Method == http.MethodPost:
+		var req bridgeapi.RoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeMessage(w, http.StatusBadRequest, "malformed request body")
+			return
+		}
+		if req.Name == "" {
+			req.Name = "u_" + newID()
+		}
+		if _, exists := c.roles[req.Name]; exists {
+			writeMessage(w, http.StatusConflict, "role already exists")
+			return
+		}
+		c.roles[req.Name] = s.newRole(c, req.Name, newID())
+		writeJSON(w, http.StatusCreated, c.roles[req.Name])
+	case len(rest) == 2 && rest[0] == "roles":
+		role, ok := c.roles[rest[1]]
+		if !ok {
+			writeMessage(w, http.StatusNotFound, "role not found")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, role)
+		case http.MethodDelete:
+			delete(c.roles, rest[1])
+			writeJSON(w, http.StatusOK, role)
+		default:
+			writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	case len(rest) == 1 && rest[0] == "replicas" && r.Method == http.MethodGet:
+		list := bridgeapi.ClusterList{Clusters: []bridgeapi.ClusterDetail{}}
+		for _, rc := range s.replicas(c) {
+			list.Clusters = append(list.Clusters, rc.detail)
+		}
+		writeJSON(w, http.StatusOK, list)
+	case len(rest) == 1 && rest[0] == "replicas" && r.Method == http.MethodPost:
+		s.createReplica(w, r, c)
+	case len(rest) == 2 && rest[0] == "replicas":
+		rc, ok := s.clusters[rest[1]]
+		if !ok || rc.primary != c.detail.ID {
+			writeMessage(w, http.StatusNotFound, "replica not found")
+			return
+		}
+		s.refresh(rc)
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, rc.detail)
+		case http.MethodDelete:
+			s.remove(rc)
+			writeJSON(w, http.StatusOK, rc.detail)
+		default:
+			writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	default:
+		writeMessage(w, http.StatusNotFound, "not found")
+	}
+}
+
+// createReplica provisions a read replica of c, which takes as long as a
+// new cluster. Blank fields of the request are taken from c.
+func (s *Server) createReplica(w http.ResponseWriter, r *http.Request, c *cluster) {
+	var req bridgeapi.ReplicaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMessage(w, http.StatusBadRequest, "malformed request body")
+		return
+	}
+	if req.Name == "" {
+		writeMessage(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if c.primary != "" {
+		writeMessage(w, http.StatusBadRequest, "replicas cannot be created from a replica")
+		return
+	}
+	for _, other := range s.clusters {
+		if other.detail.TeamID == c.detail.TeamID && other.detail.Name == req.Name {
+			writeMessage(w, http.StatusConflict, "cluster name already in use")
+			return
+		}
+	}
+
+	det := c.detail
+	det.ID = newID()
+	det.Name = req.Name
+	det.Host = hostFor(det.ID)
+	det.State = string(bridgeapi.StateCreating)
+	det.Created, det.Updated = s.now, s.now
+	det.Replicas = nil
+	if req.Plan != "" {
+		det.PlanID = req.Plan
+	}
+	if req.Provider != "" {
+		det.ProviderID = req.Provider
+	}
+	if req.Region != "" {
+		det.RegionID = req.Region
+	}
+
+	rc := &cluster{
+		detail:  det,
+		readyAt: s.now.Add(s.provisionTime),
+		roles:   map[string]bridgeapi.ConnectionRole{},
+		primary: c.detail.ID,
+	}
+	rc.roles["postgres"] = s.newRole(rc, "postgres", newID())
+	s.clusters[det.ID] = rc
+	writeJSON(w, http.StatusCreated, det)
+}
+
+// replicas returns the refreshed read replicas of c ordered by name
+func (s *Server) replicas(c *cluster) []*cluster {
+	var list []*cluster
+	for _, rc := range s.clusters {
+		if rc.primary == c.detail.ID {
+			s.refresh(rc)
+			list = append(list, rc)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].detail.Name < list[j].detail.Name
+	})
+	return list
+}
+
+// detail returns the refreshed detail of c along with its replicas
+func (s *Server) detail(c *cluster) bridgeapi.ClusterDetail {
+	s.refresh(c)
+	det := c.detail
+	det.Replicas = nil
+	for _, rc := range s.replicas(c) {
+		det.Replicas = append(det.Replicas, rc.detail)
+	}
+	return det
+}
+
+// refresh moves a creating or upgrading cluster to ready once its
+// provisioning time has passed on the server clock
+func (s *Server) refresh(c *cluster) {
+	if s.now.Before(c.readyAt) {
+		return
+	}
+	switch c.detail.State {
+	case string(bridgeapi.StateCreating):
+	case string(bridgeapi.StateUpgrading):
+		c.detail.PGMajorVersion = c.upgradeTo
+	default:
+		return
+	}
+	c.detail.State = string(bridgeapi.StateReady)
+	c.detail.Updated = s.now
+}
+
+// remove deletes c, or marks it destroying until the deletion time passes
+func (s *Server) remove(c *cluster) {
+	if s.deletionTime == 0 {
+		delete(s.clusters, c.detail.ID)
+		return
+	}
+	if c.removeAt.IsZero() {
+		c.detail.State = string(bridgeapi.StateDestroying)
+		c.detail.Updated = s.now
+		c.removeAt = s.now.Add(s.deletionTime)
+	}
+}
+
+func (s *Server) newRole(c *cluster, name, password string) bridgeapi.ConnectionRole {
+	return bridgeapi.ConnectionRole{
+		Name:     name,
+		Password: password,
+		URI: fmt.Sprintf("postgres://%s:%s@%s:5432/postgres",
+			name, password, hostFor(c.detail.ID)),
+	}
+}
+
+// hostFor returns the connection host of the identified cluster
+func hostFor(id string) string {
+	return fmt.Sprintf("p.%s.db.bridgetest.invalid", id)
+}
+
+func (a *account) canSee(teamID string) bool {
+	for _, t := range a.teams {
+		if t.ID == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeMessage responds in the APIMessage format Crunchy Bridge uses for
+// errors
+func writeMessage(w http.ResponseWriter, status int, mesg string) {
+	writeJSON(w, status, bridgeapi.APIMessage{
+		Message:   mesg,
+		RequestID: newID(),
+	})
+}
+
+var idEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newID returns a random lowercase identifier resembling Bridge IDs
+func newID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return strings.ToLower(idEncoding.EncodeToString(buf))
+}
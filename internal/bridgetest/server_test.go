@@ -0,0 +1,212 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bridgetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+)
+
+func newTestClient(t *testing.T, srv *Server, key, secret string) *bridgeapi.Client {
+	t.Helper()
+	c, err := bridgeapi.NewClient(srv.APIURL(), bridgeapi.LoginCred{Key: key, Secret: secret},
+		bridgeapi.SetRetryPolicy(bridgeapi.RetryPolicy{
+			MaxAttempts: 3,
+			Min:         time.Millisecond,
+			Max:         10 * time.Millisecond,
+		}))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	return c
+}
+
+func TestClusterLifecycle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("lifecycle", "secret")
+	client := newTestClient(t, srv, "lifecycle", "secret")
+	ctx := context.Background()
+
+	teamID, err := client.DefaultTeamID(ctx)
+	if err != nil || teamID != acctID {
+		t.Fatalf("DefaultTeamID = %q, %v; want %q", teamID, err, acctID)
+	}
+
+	catalog, err := client.ListProviders(ctx)
+	if err != nil || len(catalog.Providers) != len(DefaultProviders) {
+		t.Fatalf("ListProviders = %d providers, %v; want %d", len(catalog.Providers), err, len(DefaultProviders))
+	}
+
+	created, err := client.CreateCluster(ctx, bridgeapi.CreateRequest{
+		Name:     "lifecycle-test",
+		TeamID:   teamID,
+		Plan:     "hobby-2",
+		Provider: "aws",
+		Region:   "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	det, err := client.ClusterByName(ctx, "lifecycle-test")
+	if err != nil || det.State != string(bridgeapi.StateCreating) || det.ID != created.ID {
+		t.Fatalf("ClusterByName = %s in %q, %v; want %s creating", det.ID, det.State, err, created.ID)
+	}
+
+	srv.Advance(DefaultProvisionTime)
+	if det, err = client.ClusterDetail(ctx, det.ID); err != nil || det.State != string(bridgeapi.StateReady) {
+		t.Fatalf("ClusterDetail = %q, %v; want ready", det.State, err)
+	}
+
+	role, err := client.DefaultConnRole(ctx, det.ID)
+	if err != nil || role.Name != "postgres" || role.Password == "" {
+		t.Fatalf("DefaultConnRole = %+v, %v", role, err)
+	}
+
+	if err := client.DeleteCluster(ctx, det.ID); err != nil {
+		t.Fatalf("DeleteCluster: %v", err)
+	}
+	if _, err := client.ClusterDetail(ctx, det.ID); !errors.Is(err, bridgeapi.ErrorNotFound) {
+		t.Fatalf("ClusterDetail after delete = %v; want ErrorNotFound", err)
+	}
+	if _, err := client.ClusterByName(ctx, "lifecycle-test"); !errors.Is(err, bridgeapi.ErrorNotFound) {
+		t.Fatalf("ClusterByName after delete = %v; want ErrorNotFound", err)
+	}
+}
+
+func TestDeletionTime(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("deletion", "secret")
+	client := newTestClient(t, srv, "deletion", "secret")
+	ctx := context.Background()
+
+	srv.SetDeletionTime(time.Minute)
+	id := srv.AddCluster(bridgeapi.ClusterDetail{Name: "slow-delete", TeamID: acctID})
+	if err := client.DeleteCluster(ctx, id); err != nil {
+		t.Fatalf("DeleteCluster: %v", err)
+	}
+	if det, err := client.ClusterDetail(ctx, id); err != nil || det.State != string(bridgeapi.StateDestroying) {
+		t.Fatalf("ClusterDetail after delete = %q, %v; want destroying", det.State, err)
+	}
+
+	// The name stays taken until the cluster is gone
+	req := bridgeapi.CreateRequest{Name: "slow-delete", TeamID: acctID, Plan: "hobby-2", Provider: "aws", Region: "us-east-1"}
+	if _, err := client.CreateCluster(ctx, req); !errors.Is(err, bridgeapi.ErrorConflict) {
+		t.Fatalf("CreateCluster while destroying = %v; want ErrorConflict", err)
+	}
+
+	srv.Advance(time.Minute)
+	if _, err := client.ClusterDetail(ctx, id); !errors.Is(err, bridgeapi.ErrorNotFound) {
+		t.Fatalf("ClusterDetail after deletion time = %v; want ErrorNotFound", err)
+	}
+	if _, err := client.CreateCluster(ctx, req); err != nil {
+		t.Fatalf("CreateCluster after deletion time: %v", err)
+	}
+}
+
+func TestReplicas(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("replicas", "secret")
+	client := newTestClient(t, srv, "replicas", "secret")
+	ctx := context.Background()
+
+	primaryID := srv.AddCluster(bridgeapi.ClusterDetail{
+		Name:       "primary",
+		TeamID:     acctID,
+		PlanID:     "standard-8",
+		ProviderID: "aws",
+		RegionID:   "us-east-1",
+		State:      string(bridgeapi.StateReady),
+	})
+
+	replica, err := client.CreateReplica(ctx, primaryID, bridgeapi.ReplicaRequest{
+		Name:   "primary-west",
+		Region: "us-west-2",
+	})
+	if err != nil {
+		t.Fatalf("CreateReplica: %v", err)
+	}
+	if replica.ID == "" || replica.Host == "" || replica.PlanID != "standard-8" || replica.RegionID != "us-west-2" {
+		t.Fatalf("CreateReplica = %+v; want standard-8 replica in us-west-2 with ID and host", replica)
+	}
+	if _, err := client.CreateReplica(ctx, primaryID, bridgeapi.ReplicaRequest{Name: "primary-west"}); !errors.Is(err, bridgeapi.ErrorConflict) {
+		t.Fatalf("CreateReplica with duplicate name = %v; want ErrorConflict", err)
+	}
+
+	srv.Advance(DefaultProvisionTime)
+	list, err := client.ListReplicas(ctx, primaryID)
+	if err != nil || len(list.Clusters) != 1 || list.Clusters[0].State != string(bridgeapi.StateReady) {
+		t.Fatalf("ListReplicas = %+v, %v; want one ready replica", list.Clusters, err)
+	}
+
+	// Replicas are reported with their primary rather than listed alongside
+	all, err := client.ListAllClusters(ctx)
+	if err != nil || len(all.Clusters) != 1 || len(all.Clusters[0].Replicas) != 1 {
+		t.Fatalf("ListAllClusters = %+v, %v; want primary carrying one replica", all.Clusters, err)
+	}
+
+	if err := client.DeleteReplica(ctx, primaryID, replica.ID); err != nil {
+		t.Fatalf("DeleteReplica: %v", err)
+	}
+	if list, err := client.ListReplicas(ctx, primaryID); err != nil || len(list.Clusters) != 0 {
+		t.Fatalf("ListReplicas after delete = %+v, %v; want none", list.Clusters, err)
+	}
+	if err := client.DeleteReplica(ctx, primaryID, replica.ID); err != nil {
+		t.Fatalf("DeleteReplica of removed replica = %v; want nil", err)
+	}
+}
+
+func TestMajorUpgrade(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("upgrade", "secret")
+	client := newTestClient(t, srv, "upgrade", "secret")
+	ctx := context.Background()
+
+	id := srv.AddCluster(bridgeapi.ClusterDetail{
+		Name:           "upgraded",
+		TeamID:         acctID,
+		PlanID:         "hobby-2",
+		ProviderID:     "aws",
+		RegionID:       "us-east-1",
+		PGMajorVersion: 13,
+		State:          string(bridgeapi.StateReady),
+	})
+
+	if err := client.UpdateCluster(ctx, id, bridgeapi.UpdateRequest{PGMajorVersion: 12}); !errors.Is(err, bridgeapi.ErrorBadRequest) {
+		t.Fatalf("UpdateCluster downgrade = %v; want ErrorBadRequest", err)
+	}
+	if err := client.UpdateCluster(ctx, id, bridgeapi.UpdateRequest{PGMajorVersion: 14}); err != nil {
+		t.Fatalf("UpdateCluster upgrade: %v", err)
+	}
+	det, err := client.ClusterDetail(ctx, id)
+	if err != nil || det.State != string(bridgeapi.StateUpgrading) || det.PGMajorVersion != 13 {
+		t.Fatalf("ClusterDetail during upgrade = %+v, %v; want upgrading on 13", det, err)
+	}
+
+	srv.Advance(DefaultProvisionTime)
+	det, err = client.ClusterDetail(ctx, id)
+	if err != nil || det.State != string(bridgeapi.StateReady) || det.PGMajorVersion != 14 {
+		t.Fatalf("ClusterDetail after upgrade = %+v, %v; want ready on 14", det, err)
+	}
+}
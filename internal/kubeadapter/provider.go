@@ -27,8 +27,8 @@ import (
 // kubeSecretCredentialProvider provides a LoginCred reflecting the Client's
 // current knowledge of the secret
 type KubeSecretCredentialProvider struct {
-	// Client is a reference to the kube api client
-	Client client.Client
+	// Client is a reference to the kube api client, only used for reads
+	Client client.Reader
 	// Namespace in which the secret lives
 	Namespace string
 	// Object name of the secret
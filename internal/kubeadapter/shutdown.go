@@ -0,0 +1,38 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kubeadapter
+
+import (
+	"context"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+)
+
+// SessionCloser is a manager Runnable which closes all Crunchy Bridge login
+// sessions, revoking their tokens, when the manager shuts down
+type SessionCloser struct{}
+
+// Start blocks until ctx is done, then closes the sessions
+func (SessionCloser) Start(ctx context.Context) error {
+	<-ctx.Done()
+	bridgeapi.CloseSessions()
+	return nil
+}
+
+// NeedLeaderElection allows every replica to clean up its own sessions
+func (SessionCloser) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kubeadapter
+
+import (
+	"context"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SecretWatcher logs in again as soon as a secret backing a
+// KubeSecretCredentialProvider changes, so rotated API keys take effect
+// without waiting for the session's refresh timer. Only secrets visible to
+// the manager's cache are noticed.
+type SecretWatcher struct{}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile refreshes the sessions using the credentials in the secret
+func (sw *SecretWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if n := bridgeapi.RefreshSessions(usesSecret(req.NamespacedName)); n > 0 {
+		log.FromContext(ctx).Info("refreshed login sessions for changed credentials", "sessions", n)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the watcher with the Manager.
+func (sw *SecretWatcher) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("credentialsecret").
+		For(&corev1.Secret{}).
+		Complete(sw)
+}
+
+// usesSecret matches providers reading their credentials from the secret
+func usesSecret(key types.NamespacedName) func(bridgeapi.CredentialProvider) bool {
+	return func(cp bridgeapi.CredentialProvider) bool {
+		ks, ok := cp.(*KubeSecretCredentialProvider)
+		return ok && ks.Namespace == key.Namespace && ks.Name == key.Name
+	}
+}
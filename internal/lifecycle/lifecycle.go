@@ -0,0 +1,176 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgepoll"
+)
+
+const (
+	// DefaultProvisioningTimeout bounds provisioning when no timeout is
+	// configured
+	DefaultProvisioningTimeout = time.Hour
+
+	// RetryBackoff is the wait before the first automatic retry of a failed
+	// provisioning, doubling with each attempt up to RetryMaxBackoff
+	RetryBackoff    = time.Minute
+	RetryMaxBackoff = time.Hour
+
+	// DefaultDeletionTimeout bounds cluster removal when no timeout is
+	// configured
+	DefaultDeletionTimeout = 30 * time.Minute
+
+	// DeletionPollInterval is how often a cluster being removed is checked,
+	// in addition to the poller noticing it gone
+	DeletionPollInterval = 30 * time.Second
+)
+
+// Remaining returns the time left of timeout since started, an RFC 3339
+// timestamp, the full timeout if the start wasn't recorded
+func Remaining(started string, timeout time.Duration) time.Duration {
+	t, err := time.Parse(time.RFC3339, started)
+	if err != nil {
+		return timeout
+	}
+	return time.Until(t.Add(timeout))
+}
+
+// RetryWait returns the time left before provisioning which failed at
+// failed, an RFC 3339 timestamp, is retried after the given number of
+// attempts, backing off exponentially with each attempt
+func RetryWait(attempts int, failed string) time.Duration {
+	backoff := RetryBackoff
+	for i := 1; i < attempts && backoff < RetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > RetryMaxBackoff {
+		backoff = RetryMaxBackoff
+	}
+
+	t, err := time.Parse(time.RFC3339, failed)
+	if err != nil {
+		return 0
+	}
+	return time.Until(t.Add(backoff))
+}
+
+// FollowFailure points the poller at a cluster whose provisioning failed or
+// stalled. A stalled cluster is followed in case it comes good, a failed one
+// is no longer watched.
+func FollowFailure(
+	poller *bridgepoll.Poller,
+	events chan<- event.GenericEvent,
+	obj client.Object,
+	id, name string,
+	stalled bool) {
+
+	if stalled {
+		poller.Track(events, obj, id, name, false)
+	} else {
+		poller.Untrack(events, obj)
+	}
+}
+
+// Lookup returns the detail of the cluster identified by id, or named name
+// while its ID isn't known, as last listed by the poller. Found is false
+// while the poller has yet to list the clusters. A cluster missing from the
+// listing is looked up directly, returning an error wrapping ErrorNotFound
+// if it no longer exists.
+func Lookup(
+	ctx context.Context,
+	bc bridgeapi.Interface,
+	poller *bridgepoll.Poller,
+	id, name string) (bridgeapi.ClusterDetail, bool, error) {
+
+	det, found, err := poller.Lookup(id, name)
+	if err != nil || found || !poller.Synced() {
+		return det, found, err
+	}
+	if id == "" {
+		return det, false, fmt.Errorf("cluster %s not listed: %w", name, bridgeapi.ErrorNotFound)
+	}
+	// The listing may predate the cluster, only its detail is conclusive
+	det, err = bc.ClusterDetail(ctx, id)
+	return det, err == nil, err
+}
+
+// Removal describes the progress of a cluster being removed from Crunchy
+// Bridge
+type Removal struct {
+	// Gone is set once Bridge no longer has the cluster
+	Gone bool
+	// Stalled is set when the cluster outlasted its deletion timeout
+	Stalled bool
+	// Message explains what is being waited on while the cluster remains
+	Message string
+}
+
+// CheckRemoval reports on the removal of the cluster identified by id,
+// requested at started. Once removal takes longer than timeout the message
+// points at forceAnnotation, which releases the finalizer regardless.
+func CheckRemoval(
+	ctx context.Context,
+	bc bridgeapi.Interface,
+	id, started string,
+	timeout time.Duration,
+	forceAnnotation string) (Removal, error) {
+
+	det, err := bc.ClusterDetail(ctx, id)
+	if errors.Is(err, bridgeapi.ErrorNotFound) {
+		return Removal{Gone: true}, nil
+	} else if err != nil {
+		return Removal{}, err
+	}
+
+	if Remaining(started, timeout) > 0 {
+		return Removal{Message: fmt.Sprintf("waiting for removal of cluster %s, in state %q", id, det.State)}, nil
+	}
+	return Removal{
+		Stalled: true,
+		Message: fmt.Sprintf("cluster %s not removed within %s, in state %q; set the %s annotation to remove the finalizer regardless",
+			id, timeout, det.State, forceAnnotation),
+	}, nil
+}
+
+// ForceRemove releases finalizer from obj without waiting for the cluster
+// identified by id, recording a warning with reason that whatever remains of
+// it is left in Crunchy Bridge
+func ForceRemove(
+	ctx context.Context,
+	c client.Writer,
+	recorder record.EventRecorder,
+	obj client.Object,
+	finalizer, id, reason, forceAnnotation string) error {
+
+	log.FromContext(ctx).Info("force removing finalizer", "id", id)
+	recorder.Eventf(obj, corev1.EventTypeWarning, reason,
+		"Removed finalizer per %s annotation, cluster %q may remain in Crunchy Bridge", forceAnnotation, id)
+	controllerutil.RemoveFinalizer(obj, finalizer)
+	return c.Update(ctx, obj)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package lifecycle_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/lifecycle"
+)
+
+func TestTiming(t *testing.T) {
+	failed := time.Now().Format(time.RFC3339)
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, lifecycle.RetryBackoff},
+		{2, 2 * lifecycle.RetryBackoff},
+		{4, 8 * lifecycle.RetryBackoff},
+		{20, lifecycle.RetryMaxBackoff},
+	} {
+		// The timestamp has second precision
+		if got := lifecycle.RetryWait(tc.attempts, failed); got > tc.want || got < tc.want-time.Second {
+			t.Errorf("RetryWait(%d) = %s; want %s", tc.attempts, got, tc.want)
+		}
+	}
+	if got := lifecycle.RetryWait(1, ""); got != 0 {
+		t.Errorf("RetryWait without a failure time = %s; want 0", got)
+	}
+
+	if got := lifecycle.Remaining("", time.Hour); got != time.Hour {
+		t.Errorf("Remaining without a start = %s; want the full timeout", got)
+	}
+	started := time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
+	if got := lifecycle.Remaining(started, time.Hour); got > -time.Hour+time.Second {
+		t.Errorf("Remaining an hour past the timeout = %s", got)
+	}
+}
+
+func TestCheckRemoval(t *testing.T) {
+	srv := bridgetest.NewServer()
+	defer srv.Close()
+	acctID := srv.AddAccount("removal", "secret")
+	bc, err := bridgeapi.NewClient(srv.APIURL(), bridgeapi.LoginCred{Key: "removal", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer bc.Close()
+	ctx := context.Background()
+
+	srv.SetDeletionTime(time.Hour)
+	id := srv.AddCluster(bridgeapi.ClusterDetail{Name: "removing", TeamID: acctID})
+	if err := bc.DeleteCluster(ctx, id); err != nil {
+		t.Fatalf("DeleteCluster: %v", err)
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	removal, err := lifecycle.CheckRemoval(ctx, bc, id, now, time.Minute, "force")
+	if err != nil || removal.Gone || removal.Stalled || !strings.Contains(removal.Message, "destroying") {
+		t.Fatalf("CheckRemoval while destroying = %+v, %v", removal, err)
+	}
+
+	earlier := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	removal, err = lifecycle.CheckRemoval(ctx, bc, id, earlier, time.Minute, "force")
+	if err != nil || !removal.Stalled || !strings.Contains(removal.Message, "force") {
+		t.Fatalf("CheckRemoval past the timeout = %+v, %v; want stalled, naming the annotation", removal, err)
+	}
+
+	srv.Advance(time.Hour)
+	if removal, err = lifecycle.CheckRemoval(ctx, bc, id, now, time.Minute, "force"); err != nil || !removal.Gone {
+		t.Fatalf("CheckRemoval once removed = %+v, %v; want gone", removal, err)
+	}
+}
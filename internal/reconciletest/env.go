@@ -0,0 +1,249 @@
+/*
+Copyright 2021 Crunchy Data Solutions, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package reconciletest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgepoll"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgetest"
+)
+
+const (
+	// Namespace holds the objects created by tests
+	Namespace = "default"
+
+	// Key and Secret are the API credentials of the Bridge account the
+	// reconcilers use
+	Key    = "operator"
+	Secret = "secret"
+
+	// timeout bounds ReconcileUntil
+	timeout = 5 * time.Second
+)
+
+// Env holds what a reconciler under test talks to. Reconciles are driven by
+// the test, notifications sent to Events by pollers are dropped.
+type Env struct {
+	Ctx      context.Context
+	Server   *bridgetest.Server
+	TeamID   string
+	Bridge   *bridgeapi.Client
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder *record.FakeRecorder
+	Pollers  *bridgepoll.Registry
+	Events   chan event.GenericEvent
+
+	t      *testing.T
+	events []string
+}
+
+// New returns an Env whose Kubernetes client knows the built-in types and
+// those added by addToScheme. It is torn down when the test finishes.
+func New(t *testing.T, addToScheme ...func(*runtime.Scheme) error) *Env {
+	t.Helper()
+	srv := bridgetest.NewServer()
+	t.Cleanup(srv.Close)
+	e := &Env{
+		Server:   srv,
+		TeamID:   srv.AddAccount(Key, Secret),
+		Scheme:   runtime.NewScheme(),
+		Recorder: record.NewFakeRecorder(1000),
+		Pollers:  &bridgepoll.Registry{MinInterval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond},
+		Events:   make(chan event.GenericEvent),
+		t:        t,
+	}
+
+	bc, err := e.NewBridgeClient()
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { bc.Close() })
+	e.Bridge = bc
+
+	for _, add := range append([]func(*runtime.Scheme) error{clientgoscheme.AddToScheme}, addToScheme...) {
+		if err := add(e.Scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Client = fake.NewClientBuilder().WithScheme(e.Scheme).Build()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	e.Ctx = ctx
+	go e.Pollers.Start(ctx)
+	go func() {
+		for {
+			select {
+			case <-e.Events:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return e
+}
+
+// NewBridgeClient returns a client of the Env account which gives up on
+// failed requests quickly, leaving injected faults to the reconciler
+func (e *Env) NewBridgeClient() (*bridgeapi.Client, error) {
+	return bridgeapi.NewClient(e.Server.APIURL(), bridgeapi.LoginCred{Key: Key, Secret: Secret},
+		bridgeapi.SetRetryPolicy(bridgeapi.RetryPolicy{MaxAttempts: 2, Min: time.Millisecond, Max: time.Millisecond}))
+}
+
+// Create stores obj, which is in Namespace unless cluster scoped
+func (e *Env) Create(obj client.Object) {
+	e.t.Helper()
+	if err := e.Client.Create(e.Ctx, obj); err != nil {
+		e.t.Fatalf("creating %s: %v", obj.GetName(), err)
+	}
+}
+
+// Get refreshes obj, reporting false once it is gone
+func (e *Env) Get(obj client.Object) bool {
+	e.t.Helper()
+	err := e.Client.Get(e.Ctx, client.ObjectKeyFromObject(obj), obj)
+	if apierrors.IsNotFound(err) {
+		return false
+	} else if err != nil {
+		e.t.Fatalf("getting %s: %v", obj.GetName(), err)
+	}
+	return true
+}
+
+// Modify refreshes obj and stores it again after change, status included
+func (e *Env) Modify(obj client.Object, change func()) {
+	e.t.Helper()
+	if !e.Get(obj) {
+		e.t.Fatalf("modifying %s: not found", obj.GetName())
+	}
+	change()
+	if err := e.Client.Update(e.Ctx, obj); err != nil {
+		e.t.Fatalf("updating %s: %v", obj.GetName(), err)
+	}
+}
+
+// Delete deletes obj, which stays around until its finalizers are removed
+func (e *Env) Delete(obj client.Object) {
+	e.t.Helper()
+	if err := e.Client.Delete(e.Ctx, obj); err != nil {
+		e.t.Fatalf("deleting %s: %v", obj.GetName(), err)
+	}
+}
+
+// ReconcileUntil reconciles obj with r until done accepts it, giving pollers
+// time to catch up between passes. obj is refreshed before each call of
+// done, which is told whether obj still exists.
+func (e *Env) ReconcileUntil(r reconcile.Reconciler, obj client.Object, done func(found bool) bool) {
+	e.t.Helper()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)}
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := r.Reconcile(e.Ctx, req)
+		if done(e.Get(obj)) {
+			return
+		}
+		if time.Now().After(deadline) {
+			e.t.Fatalf("%s not reconciled as expected, last error %v, status %s", obj.GetName(), err, status(obj))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// ReconcileTimes reconciles obj with r n times, returning the last error.
+// obj is refreshed afterwards.
+func (e *Env) ReconcileTimes(r reconcile.Reconciler, obj client.Object, n int) error {
+	e.t.Helper()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)}
+	var err error
+	for i := 0; i < n; i++ {
+		_, err = r.Reconcile(e.Ctx, req)
+		time.Sleep(10 * time.Millisecond)
+	}
+	e.Get(obj)
+	return err
+}
+
+// Recorded reports whether an event with reason has been recorded
+func (e *Env) Recorded(reason string) bool {
+	for drained := false; !drained; {
+		select {
+		case ev := <-e.Recorder.Events:
+			e.events = append(e.events, ev)
+		default:
+			drained = true
+		}
+	}
+	for _, ev := range e.events {
+		if strings.Fields(ev)[1] == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// AddReadyCluster adds a ready cluster named name to the Env account,
+// returning its ID
+func (e *Env) AddReadyCluster(name string) string {
+	return e.Server.AddCluster(bridgeapi.ClusterDetail{
+		Name: name, TeamID: e.TeamID, PlanID: "hobby-2", StorageGB: 10,
+		ProviderID: "aws", RegionID: "us-east-1", PGMajorVersion: 13, State: string(bridgeapi.StateReady),
+	})
+}
+
+// ClustersNamed returns the clusters of the Env account named name
+func (e *Env) ClustersNamed(name string) []bridgeapi.ClusterDetail {
+	e.t.Helper()
+	list, err := e.Bridge.ListAllClusters(e.Ctx)
+	if err != nil {
+		e.t.Fatalf("ListAllClusters: %v", err)
+	}
+	var named []bridgeapi.ClusterDetail
+	for _, det := range list.Clusters {
+		if det.Name == name {
+			named = append(named, det)
+		}
+	}
+	return named
+}
+
+// status returns the status of obj for failure messages
+func status(obj client.Object) string {
+	var fields struct {
+		Status json.RawMessage `json:"status"`
+	}
+	if b, err := json.Marshal(obj); err != nil || json.Unmarshal(b, &fields) != nil {
+		return "unavailable"
+	}
+	return string(fields.Status)
+}
+
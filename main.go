@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"net/url"
 	"os"
@@ -44,6 +45,7 @@ import (
 
 	//+kubebuilder:scaffold:imports
 	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgeapi"
+	"github.com/CrunchyData/crunchy-bridge-operator/internal/bridgepoll"
 	"github.com/CrunchyData/crunchy-bridge-operator/internal/kubeadapter"
 )
 
@@ -62,7 +64,7 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
-var dbaasInit func(manager.Options, string) manager.Manager
+var dbaasInit func(manager.Options, string, *bridgepoll.Registry) manager.Manager
 
 func main() {
 	// Variables from boilerplate
@@ -70,6 +72,10 @@ func main() {
 	var enableLeaderElection bool
 	var crunchybridgeAPIURL string
 	var syncPeriod time.Duration
+	var apiTimeout time.Duration
+	var credSource, credFileDir, credExecCommand string
+	var credExecArgs stringList
+	var credEnvKey, credEnvSecret string
 
 	// Namespace and Name for APIKey secret default values
 	credNamespace := "default"
@@ -84,6 +90,13 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.DurationVar(&syncPeriod, "sync-period-min", 180*time.Minute, "The minimum interval at which watched resources are reconciled (e.g. 30 minutes)")
+	flag.DurationVar(&apiTimeout, "api-request-timeout", bridgeapi.DefaultRequestTimeout, "The time limit for each Crunchy Bridge API request (e.g. 30s)")
+	flag.StringVar(&credSource, "credential-source", "secret", "Where the operator-wide API credentials come from: secret, file, env or exec")
+	flag.StringVar(&credFileDir, "credential-file-dir", "/etc/crunchybridge", "The directory holding credential files named after the key and secret fields, with credential-source=file")
+	flag.StringVar(&credEnvKey, "credential-env-key", "CRUNCHY_BRIDGE_API_KEY", "The environment variable holding the API key, with credential-source=env")
+	flag.StringVar(&credEnvSecret, "credential-env-secret", "CRUNCHY_BRIDGE_API_SECRET", "The environment variable holding the API secret, with credential-source=env")
+	flag.StringVar(&credExecCommand, "credential-exec-command", "", "The helper command printing credentials as JSON, with credential-source=exec")
+	flag.Var(&credExecArgs, "credential-exec-arg", "An argument for the credential helper command, repeated for each argument")
 
 	opts := zap.Options{
 		Development: true,
@@ -137,9 +150,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// One registry serves every controller following Bridge clusters, so
+	// that each account is polled once
+	pollers := &bridgepoll.Registry{Log: ctrl.Log.WithName("bridgepoll")}
+
 	// Set up manager with DBaaS controllers if built with option
 	if dbaasInit != nil {
-		mgr = dbaasInit(mgrOpts, crunchybridgeAPIURL)
+		mgr = dbaasInit(mgrOpts, crunchybridgeAPIURL, pollers)
+	}
+	if err := mgr.Add(pollers); err != nil {
+		setupLog.Error(err, "unable to set up cluster polling")
+		os.Exit(1)
 	}
 
 	// Create client directly for querying non-managed object
@@ -149,43 +170,110 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize credential provider from environment
-	ksp := &kubeadapter.KubeSecretCredentialProvider{
-		Client:      crClient,
-		Namespace:   credNamespace,
-		Name:        credName,
-		KeyField:    keyField,
-		SecretField: keySecret,
+	// Initialize credential provider from flags and environment
+	var defaultCreds bridgeapi.CredentialProvider
+	switch credSource {
+	case "secret":
+		defaultCreds = &kubeadapter.KubeSecretCredentialProvider{
+			Client:      crClient,
+			Namespace:   credNamespace,
+			Name:        credName,
+			KeyField:    keyField,
+			SecretField: keySecret,
+		}
+	case "file":
+		fcp := &bridgeapi.FileCredentialProvider{
+			Dir:        credFileDir,
+			KeyFile:    keyField,
+			SecretFile: keySecret,
+		}
+		if err := mgr.Add(fcp); err != nil {
+			setupLog.Error(err, "unable to watch credential files")
+			os.Exit(1)
+		}
+		defaultCreds = fcp
+	case "env":
+		defaultCreds = &bridgeapi.EnvCredentialProvider{
+			KeyVar:    credEnvKey,
+			SecretVar: credEnvSecret,
+		}
+	case "exec":
+		if credExecCommand == "" {
+			setupLog.Info("credential-exec-command is required with credential-source=exec")
+			os.Exit(1)
+		}
+		defaultCreds = &bridgeapi.ExecCredentialProvider{
+			Command: credExecCommand,
+			Args:    credExecArgs,
+		}
+	default:
+		setupLog.Info("unrecognized credential source", "credential-source", credSource)
+		os.Exit(1)
 	}
 
-	runBridgeControllers := true
-
-	bridgeClient, err := bridgeapi.NewClient(apiURL, ksp,
+	clientOpts := []bridgeapi.ClientOption{
 		bridgeapi.SetLogger(setupLog),
-		bridgeapi.SetVersion(operatorVersion))
-	if err != nil {
-		setupLog.Info("unable to configure Crunchy Bridge API client for crunchybridge controllers, disabling")
-		runBridgeControllers = false
-	}
-
-	if runBridgeControllers {
-		if err = (&crunchybridgecontrollers.BridgeClusterReconciler{
-			Client:       mgr.GetClient(),
-			Scheme:       mgr.GetScheme(),
-			BridgeClient: bridgeClient,
-			WatchInt:     10 * time.Second,
-		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "BridgeCluster")
+		bridgeapi.SetRequestTimeout(apiTimeout),
+		bridgeapi.SetVersion(operatorVersion),
+	}
+
+	// Objects without an account_ref use the operator-wide credentials,
+	// objects referencing a BridgeAccount get a client of their own. The
+	// controllers start regardless, so credentials created after the
+	// operator are picked up without a restart
+	accounts := &crunchybridgecontrollers.AccountClients{
+		Reader:             mgr.GetAPIReader(),
+		DefaultCredentials: defaultCreds,
+		APIURL:             apiURL,
+		Options:            clientOpts,
+	}
+	if _, err := accounts.ClientFor(context.Background(), ""); err != nil {
+		setupLog.Info("default Crunchy Bridge API credentials not yet available, will retry",
+			"source", credSource, "error", err.Error())
+	}
+
+	if err = accounts.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BridgeAccount")
+		os.Exit(1)
+	}
+	if err = (&crunchybridgecontrollers.BridgeClusterReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Accounts: accounts,
+		Pollers:  pollers,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BridgeCluster")
+		os.Exit(1)
+	}
+	if err = (&crunchybridgecontrollers.DatabaseRoleReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Accounts: accounts,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DatabaseRole")
+		os.Exit(1)
+	}
+	// Webhooks need serving certificates, which only the config/openshift
+	// overlay and OLM provide, so they are off unless asked for
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&crunchybridgev1alpha1.BridgeCluster{}).SetupWebhookWithManager(mgr, accounts); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BridgeCluster")
 			os.Exit(1)
 		}
-		if err = (&crunchybridgecontrollers.DatabaseRoleReconciler{
-			Client: mgr.GetClient(),
-			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "DatabaseRole")
+	}
+	// The watcher reconciles every secret change, which is only worth it
+	// when the operator-wide credentials come from a secret. Sessions of
+	// BridgeAccounts otherwise pick up rotated secrets on their refresh timer
+	if credSource == "secret" {
+		if err = (&kubeadapter.SecretWatcher{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CredentialSecret")
 			os.Exit(1)
 		}
 	}
+	if err = mgr.Add(kubeadapter.SessionCloser{}); err != nil {
+		setupLog.Error(err, "unable to set up API session cleanup")
+		os.Exit(1)
+	}
 
 	//+kubebuilder:scaffold:builder
 
@@ -197,6 +285,14 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// DBaaS installs take credentials per inventory rather than from the
+	// operator-wide secret, so its absence must not hold back readiness
+	if dbaasInit == nil {
+		if err := mgr.AddReadyzCheck("bridge-login", accounts.LoginCheck); err != nil {
+			setupLog.Error(err, "unable to set up login check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -204,3 +300,15 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// stringList collects the values of a flag given more than once
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}